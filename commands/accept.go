@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"strings"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+type AcceptCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("ACCEPT", func(s *server.Server) server.Command {
+		return &AcceptCommand{server: s}
+	})
+}
+
+func (c *AcceptCommand) Name() string {
+	return "ACCEPT"
+}
+
+func (c *AcceptCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	fields := strings.Fields(params)
+	if len(fields) == 0 {
+		client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "ACCEPT :Not enough parameters")
+		return
+	}
+
+	for _, nick := range strings.Split(fields[0], ",") {
+		switch {
+		case nick == "*":
+			for _, accepted := range client.GetAcceptList() {
+				client.SendNumeric(utils.RPL_ACCEPTLIST, accepted)
+			}
+			client.SendNumeric(utils.RPL_ENDOFACCEPT, ":End of /ACCEPT list")
+
+		case strings.HasPrefix(nick, "-"):
+			nick = nick[1:]
+			if !client.RemoveAccept(nick) {
+				client.SendNumeric(utils.ERR_ACCEPTNOT, nick+" :is not on your accept list")
+			}
+
+		case nick != "":
+			target := c.server.FindClient(nick)
+			if target == nil {
+				client.SendNumeric(utils.ERR_NOSUCHNICK, nick+" :No such nick/channel")
+				continue
+			}
+
+			if len(client.GetAcceptList()) >= utils.MAX_ACCEPT_ENTRIES {
+				client.SendNumeric(utils.ERR_ACCEPTFULL, ":Accept list is full")
+				continue
+			}
+
+			if !client.AddAccept(target.Nick) {
+				client.SendNumeric(utils.ERR_ACCEPTEXIST, target.Nick+" :is already on your accept list")
+			}
+		}
+	}
+}
+
+func (c *AcceptCommand) Help() string {
+	return "ACCEPT <nick>{,<nick>} | ACCEPT -<nick> | ACCEPT * - Manages who may message you while you have user mode +g"
+}
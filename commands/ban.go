@@ -3,6 +3,7 @@ package commands
 import (
 	"goircd/logger"
 	"strings"
+	"time"
 
 	"goircd/server"
 	"goircd/utils"
@@ -28,7 +29,7 @@ func (c *BanCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
-	parts := strings.SplitN(params, " ", 2)
+	parts := strings.Fields(params)
 	if len(parts) < 2 {
 		client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "BAN :Not enough parameters")
 		return
@@ -37,12 +38,24 @@ func (c *BanCommand) Execute(client *server.Client, params string) {
 	channelName := parts[0]
 	banMask := parts[1]
 
+	var duration time.Duration
+	if len(parts) > 2 {
+		var ok bool
+		duration, ok = utils.ParseDuration(parts[2])
+		if !ok {
+			client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "BAN :Invalid ban duration")
+			return
+		}
+	}
+
 	channel := c.server.GetChannel(channelName)
 	if channel == nil {
 		client.SendNumeric(utils.ERR_NOSUCHCHANNEL, channelName+" :No such channel")
 		return
 	}
 
+	channelName = channel.Name
+
 	if !channel.HasClient(client) {
 		client.SendNumeric(utils.ERR_NOTONCHANNEL, channelName+" :You're not on that channel")
 		return
@@ -56,7 +69,7 @@ func (c *BanCommand) Execute(client *server.Client, params string) {
 	if !strings.ContainsAny(banMask, "*?") && !strings.Contains(banMask, "!") && !strings.Contains(banMask, "@") {
 		targetClient := c.server.GetClient(banMask)
 		if targetClient != nil {
-			banMask = utils.FormatMask(targetClient.Nick, targetClient.User, targetClient.Host)
+			banMask = utils.FormatMask(targetClient.Nick, targetClient.User, targetClient.GetHost())
 		} else {
 			banMask = banMask + "!*@*"
 		}
@@ -67,14 +80,23 @@ func (c *BanCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
-	channel.SetBanned(banMask, true)
+	if !channel.HasBan(banMask) && channel.ListSize() >= utils.MAX_BANS_PER_CHANNEL {
+		client.SendNumeric(utils.ERR_BANLISTFULL, channelName+" b :Channel list is full")
+		return
+	}
 
-	logger.Ban(client.Nick, banMask, channelName, "")
+	channel.AddBan(banMask, client.Prefix(), duration)
+
+	reason := ""
+	if duration > 0 {
+		reason = "expires in " + utils.FormatDuration(duration)
+	}
+	logger.Ban(client.Nick, banMask, channelName, reason)
 
-	modeMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " MODE " + channelName + " +b " + banMask
+	modeMsg := ":" + client.Prefix() + " MODE " + channelName + " +b " + banMask
 	channel.Broadcast(modeMsg)
 }
 
 func (c *BanCommand) Help() string {
-	return "BAN <channel> <mask> - Bans users matching the mask from the channel"
+	return "BAN <channel> <mask> [<duration>] - Bans users matching the mask from the channel, optionally for a time such as 30m, 2h or 1d"
 }
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"strings"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+type CapCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("CAP", func(s *server.Server) server.Command {
+		return &CapCommand{server: s}
+	})
+}
+
+func (c *CapCommand) Name() string {
+	return "CAP"
+}
+
+func (c *CapCommand) Execute(client *server.Client, params string) {
+	subcommand, args, _ := strings.Cut(strings.TrimSpace(params), " ")
+	subcommand = strings.ToUpper(subcommand)
+	if subcommand == "" {
+		client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "CAP :Not enough parameters")
+		return
+	}
+
+	switch subcommand {
+	case "LS":
+		if !client.IsRegistered() {
+			client.BeginCapNegotiation()
+		}
+		c.reply(client, "LS", strings.Join(server.SupportedCaps(), " "))
+	case "LIST":
+		c.reply(client, "LIST", strings.Join(client.EnabledCaps(), " "))
+	case "REQ":
+		if !client.IsRegistered() {
+			client.BeginCapNegotiation()
+		}
+		c.request(client, strings.TrimPrefix(strings.TrimSpace(args), ":"))
+	case "END":
+		if client.EndCapNegotiation() && client.Nick != "" && client.User != "" && !client.IsRegistered() {
+			c.server.CompleteRegistration(client)
+		}
+	default:
+		client.SendNumeric(utils.ERR_INVALIDCAPCMD, subcommand+" :Invalid CAP command")
+	}
+}
+
+// request applies a CAP REQ. The request is all or nothing: if any
+// capability is unknown, none of them change.
+func (c *CapCommand) request(client *server.Client, requested string) {
+	names := strings.Fields(requested)
+	if len(names) == 0 {
+		c.reply(client, "NAK", requested)
+		return
+	}
+
+	for _, name := range names {
+		if !server.IsSupportedCap(strings.TrimPrefix(name, "-")) {
+			c.reply(client, "NAK", requested)
+			return
+		}
+	}
+
+	for _, name := range names {
+		if strings.HasPrefix(name, "-") {
+			client.SetCap(name[1:], false)
+		} else {
+			client.SetCap(name, true)
+		}
+	}
+
+	c.reply(client, "ACK", requested)
+}
+
+func (c *CapCommand) reply(client *server.Client, subcommand, text string) {
+	target := client.Nick
+	if target == "" {
+		target = "*"
+	}
+	client.Send(":" + utils.SERVER_NAME + " CAP " + target + " " + subcommand + " :" + text)
+}
+
+func (c *CapCommand) Help() string {
+	return "CAP <LS|LIST|REQ|END> [:<capabilities>] - Negotiates optional protocol features"
+}
@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"goircd/config"
+	"goircd/server"
+	"goircd/utils"
+)
+
+type ChatHistoryCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("CHATHISTORY", func(s *server.Server) server.Command {
+		return &ChatHistoryCommand{server: s}
+	})
+}
+
+func (c *ChatHistoryCommand) Name() string {
+	return "CHATHISTORY"
+}
+
+func (c *ChatHistoryCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	history := c.server.History()
+	if history == nil {
+		client.SendNumeric(utils.ERR_UNKNOWNCOMMAND, "CHATHISTORY :Unknown command")
+		return
+	}
+
+	args := strings.Fields(params)
+	if len(args) < 1 {
+		client.SendFail("CHATHISTORY", "NEED_MORE_PARAMS", "", "Missing parameters")
+		return
+	}
+
+	subcommand := strings.ToUpper(args[0])
+	needed := map[string]int{
+		"LATEST":  4,
+		"BEFORE":  4,
+		"AFTER":   4,
+		"AROUND":  4,
+		"BETWEEN": 5,
+		"TARGETS": 4,
+	}
+
+	required, known := needed[subcommand]
+	if !known {
+		client.SendFail("CHATHISTORY", "INVALID_PARAMS", args[0], "Unknown subcommand")
+		return
+	}
+
+	if len(args) < required {
+		client.SendFail("CHATHISTORY", "NEED_MORE_PARAMS", subcommand, "Missing parameters")
+		return
+	}
+
+	limit, err := strconv.Atoi(args[required-1])
+	if err != nil || limit < 1 {
+		client.SendFail("CHATHISTORY", "INVALID_PARAMS", subcommand, "Invalid limit")
+		return
+	}
+	limit = min(limit, config.Get().History.QueryLimit)
+
+	if subcommand == "TARGETS" {
+		c.sendTargets(client, history, args[1], args[2], limit)
+		return
+	}
+
+	target := args[1]
+	key, ok := c.historyKey(client, target)
+	if !ok {
+		client.SendFail("CHATHISTORY", "INVALID_TARGET", subcommand+" "+target, "Messages could not be retrieved")
+		return
+	}
+
+	first, ok := parseHistorySelector(args[2], subcommand == "LATEST")
+	if !ok {
+		client.SendFail("CHATHISTORY", "INVALID_PARAMS", subcommand+" "+args[2], "Invalid message reference")
+		return
+	}
+
+	var entries []server.HistoryEntry
+
+	switch subcommand {
+	case "LATEST":
+		entries = history.Latest(key, first, limit)
+	case "BEFORE":
+		entries = history.Before(key, first, limit)
+	case "AFTER":
+		entries = history.After(key, first, limit)
+	case "AROUND":
+		entries = history.Around(key, first, limit)
+	case "BETWEEN":
+		second, ok := parseHistorySelector(args[3], false)
+		if !ok {
+			client.SendFail("CHATHISTORY", "INVALID_PARAMS", subcommand+" "+args[3], "Invalid message reference")
+			return
+		}
+		entries = history.Between(key, first, second, limit)
+	}
+
+	batchRef := startBatch(client, "chathistory "+target)
+
+	for _, entry := range entries {
+		tags := entry.Tags()
+		tags["batch"] = batchRef
+		client.SendTagged(tags, ":"+entry.Source+" "+entry.Command+" "+entry.Target+" :"+entry.Text)
+	}
+
+	endBatch(client, batchRef)
+}
+
+// startBatch opens a batch of the given type and parameters and returns its
+// reference. Clients without the batch capability get the messages without
+// the BATCH lines around them.
+func startBatch(client *server.Client, batchType string) string {
+	batchRef := utils.GenerateMsgID()
+	if client.HasCap(server.CapBatch) {
+		client.Send(":" + utils.SERVER_NAME + " BATCH +" + batchRef + " " + batchType)
+	}
+	return batchRef
+}
+
+func endBatch(client *server.Client, batchRef string) {
+	if client.HasCap(server.CapBatch) {
+		client.Send(":" + utils.SERVER_NAME + " BATCH -" + batchRef)
+	}
+}
+
+// historyKey resolves a CHATHISTORY target to a history key, refusing
+// channels the client is not in. Secret and private channels are refused in
+// exactly the same way as missing ones so their existence is not revealed.
+// Only channel history is kept: private messages could only be tied to a
+// nick, which anyone can take after a disconnect, so nicks are refused too.
+func (c *ChatHistoryCommand) historyKey(client *server.Client, target string) (string, bool) {
+	if !strings.HasPrefix(target, "#") && !strings.HasPrefix(target, "&") {
+		return "", false
+	}
+
+	channel := c.server.GetChannel(target)
+	if channel == nil || !channel.HasClient(client) {
+		return "", false
+	}
+	return server.ChannelHistoryKey(channel.Name), true
+}
+
+func (c *ChatHistoryCommand) sendTargets(client *server.Client, history *server.HistoryStore, fromArg, toArg string, limit int) {
+	from, okFrom := parseHistorySelector(fromArg, false)
+	to, okTo := parseHistorySelector(toArg, false)
+	if !okFrom || !okTo || from.MsgID != "" || to.MsgID != "" {
+		client.SendFail("CHATHISTORY", "INVALID_PARAMS", "TARGETS", "TARGETS only accepts timestamps")
+		return
+	}
+
+	joined := make(map[string]bool)
+	for _, channel := range client.GetChannels() {
+		joined[server.ChannelHistoryKey(channel.Name)] = true
+	}
+
+	targets := history.Targets(func(key string) bool {
+		return joined[key]
+	}, from.Time, to.Time, limit)
+
+	batchRef := startBatch(client, "draft/chathistory-targets")
+
+	for _, target := range targets {
+		tags := map[string]string{"batch": batchRef}
+		client.SendTagged(tags, ":"+utils.SERVER_NAME+" CHATHISTORY TARGETS "+target.Name+" "+utils.FormatServerTime(target.Time))
+	}
+
+	endBatch(client, batchRef)
+}
+
+func parseHistorySelector(value string, allowWildcard bool) (server.HistorySelector, bool) {
+	if value == "*" {
+		return server.HistorySelector{}, allowWildcard
+	}
+
+	kind, ref, found := strings.Cut(value, "=")
+	if !found || ref == "" {
+		return server.HistorySelector{}, false
+	}
+
+	switch kind {
+	case "msgid":
+		return server.HistorySelector{MsgID: ref}, true
+	case "timestamp":
+		t, ok := utils.ParseServerTime(ref)
+		if !ok {
+			return server.HistorySelector{}, false
+		}
+		return server.HistorySelector{Time: t}, true
+	}
+
+	return server.HistorySelector{}, false
+}
+
+func (c *ChatHistoryCommand) Help() string {
+	return "CHATHISTORY <LATEST|BEFORE|AFTER|AROUND> <target> <reference> <limit> | " +
+		"CHATHISTORY BETWEEN <target> <reference> <reference> <limit> | " +
+		"CHATHISTORY TARGETS <timestamp> <timestamp> <limit> - Retrieves the message history of channels you are in"
+}
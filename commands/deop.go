@@ -43,6 +43,8 @@ func (c *DeOpCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
+	channelName = channel.Name
+
 	if !channel.HasClient(client) {
 		client.SendNumeric(utils.ERR_NOTONCHANNEL, channelName+" :You're not on that channel")
 		return
@@ -73,7 +75,7 @@ func (c *DeOpCommand) Execute(client *server.Client, params string) {
 
 	logger.ChannelOp(client.Nick, "removed operator status from", targetNick, channelName)
 
-	modeMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " MODE " + channelName + " -o " + targetNick
+	modeMsg := ":" + client.Prefix() + " MODE " + channelName + " -o " + targetNick
 	channel.Broadcast(modeMsg)
 }
 
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"strings"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+type EntryMsgCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("ENTRYMSG", func(s *server.Server) server.Command {
+		return &EntryMsgCommand{server: s}
+	})
+}
+
+func (c *EntryMsgCommand) Name() string {
+	return "ENTRYMSG"
+}
+
+func (c *EntryMsgCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	if params == "" {
+		client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "ENTRYMSG :Not enough parameters")
+		return
+	}
+
+	parts := strings.SplitN(params, " ", 2)
+	channelName := parts[0]
+
+	channel := c.server.GetChannel(channelName)
+	if channel == nil {
+		client.SendNumeric(utils.ERR_NOSUCHCHANNEL, channelName+" :No such channel")
+		return
+	}
+
+	channelName = channel.Name
+
+	if !channel.HasClient(client) {
+		client.SendNumeric(utils.ERR_NOTONCHANNEL, channelName+" :You're not on that channel")
+		return
+	}
+
+	if len(parts) == 1 {
+		if message := channel.GetEntryMessage(); message != "" {
+			c.notice(client, "Entry message for "+channel.Name+": "+message)
+		} else {
+			c.notice(client, "No entry message is set for "+channel.Name)
+		}
+		return
+	}
+
+	if !channel.IsOperator(client) {
+		client.SendNumeric(utils.ERR_CHANOPRIVSNEEDED, channelName+" :You're not channel operator")
+		return
+	}
+
+	message := utils.TruncateText(strings.TrimPrefix(parts[1], ":"), utils.MAX_TOPIC_LENGTH)
+
+	channel.SetEntryMessage(message)
+
+	if message == "" {
+		c.notice(client, "Entry message for "+channel.Name+" cleared")
+	} else {
+		c.notice(client, "Entry message for "+channel.Name+" set to: "+message)
+	}
+}
+
+func (c *EntryMsgCommand) notice(client *server.Client, text string) {
+	client.Send(":" + utils.SERVER_NAME + " NOTICE " + client.Nick + " :" + text)
+}
+
+func (c *EntryMsgCommand) Help() string {
+	return "ENTRYMSG <channel> [:<message>] - Shows or sets the notice sent to users joining the channel; an empty message clears it"
+}
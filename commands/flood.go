@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"time"
+
+	"goircd/logger"
+	"goircd/server"
+	"goircd/utils"
+)
+
+// isFloodExempt reports whether client bypasses channel flood protection.
+func isFloodExempt(channel *server.Channel, client *server.Client) bool {
+	return channel.IsOperator(client) || client.IsOperator()
+}
+
+// applyFloodAction carries out the +F action for a violation by offender.
+// Channel-wide violations always moderate the channel. Quiets and bans are
+// set as timed entries and moderation is lifted once the +F expiry time has
+// passed.
+func applyFloodAction(s *server.Server, channel *server.Channel, offender *server.Client, violation server.FloodViolation) {
+	settings := channel.GetFloodSettings()
+	if settings == nil || violation == server.FloodNone {
+		return
+	}
+
+	action := settings.Action
+	if violation == server.FloodChannel {
+		action = server.FloodActionModerate
+	}
+
+	logger.Info("FLOOD: %s triggered flood protection in %s (%s)", offender.Nick, channel.Name, action)
+
+	mask := utils.FormatMask("*", "*", offender.GetHost())
+
+	switch action {
+	case server.FloodActionQuiet:
+		if channel.HasQuiet(mask) {
+			return
+		}
+
+		channel.AddQuiet(mask, utils.SERVER_NAME, settings.Expire)
+		sendServerMode(channel, "+q", mask)
+
+	case server.FloodActionBan:
+		if !channel.HasBan(mask) {
+			channel.AddBan(mask, utils.SERVER_NAME, settings.Expire)
+			logger.Ban(utils.SERVER_NAME, mask, channel.Name, "Flood detected")
+			sendServerMode(channel, "+b", mask)
+		}
+
+		kickForFlood(s, channel, offender)
+
+	case server.FloodActionKick:
+		kickForFlood(s, channel, offender)
+
+	case server.FloodActionModerate:
+		if channel.HasMode(server.ModeModerated) {
+			return
+		}
+
+		channel.SetMode(server.ModeModerated, true)
+		channel.SetFloodModerated(true)
+		sendServerMode(channel, "+m", "")
+
+		time.AfterFunc(settings.Expire, func() {
+			if channel.IsFloodModerated() {
+				channel.SetMode(server.ModeModerated, false)
+				channel.SetFloodModerated(false)
+				sendServerMode(channel, "-m", "")
+			}
+		})
+	}
+}
+
+func kickForFlood(s *server.Server, channel *server.Channel, offender *server.Client) {
+	if !channel.HasClient(offender) {
+		return
+	}
+
+	logger.Kick(utils.SERVER_NAME, offender.Nick, channel.Name, "Flood detected")
+
+	channel.Broadcast(":" + utils.SERVER_NAME + " KICK " + channel.Name + " " + offender.Nick + " :Flood detected")
+
+	channel.RecordKick(offender)
+	s.RemoveFromChannel(channel, offender)
+}
+
+func sendServerMode(channel *server.Channel, change, param string) {
+	modeMsg := ":" + utils.SERVER_NAME + " MODE " + channel.Name + " " + change
+	if param != "" {
+		modeMsg += " " + param
+	}
+	channel.Broadcast(modeMsg)
+}
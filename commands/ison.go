@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"strings"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+type IsonCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("ISON", func(s *server.Server) server.Command {
+		return &IsonCommand{server: s}
+	})
+}
+
+func (c *IsonCommand) Name() string {
+	return "ISON"
+}
+
+func (c *IsonCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	// The nicks may also be sent as a single trailing parameter.
+	nicknames := strings.Fields(strings.Replace(params, ":", "", 1))
+	if len(nicknames) == 0 {
+		client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "ISON :Not enough parameters")
+		return
+	}
+
+	var online []string
+	for _, target := range findClients(c.server, nicknames) {
+		online = append(online, target.Nick)
+	}
+
+	client.SendNumeric(utils.RPL_ISON, ":"+strings.Join(online, " "))
+}
+
+func (c *IsonCommand) Help() string {
+	return "ISON <nickname> [<nickname> ...] - Shows which of the given nicknames are online"
+}
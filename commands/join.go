@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"goircd/config"
+	"math"
 	"strings"
 
 	"goircd/server"
@@ -60,74 +61,179 @@ func (c *JoinCommand) Execute(client *server.Client, params string) {
 			key = keys[i]
 		}
 
-		channel := c.server.GetChannel(channelName)
-		isNewChannel := false
+		c.joinChannel(client, channelName, key, map[string]bool{strings.ToLower(channelName): true})
+	}
+}
 
-		if channel == nil {
-			if len(channelName) > cfg.Security.MaxChannelName {
-				client.SendNumeric(utils.ERR_BADCHANMASK, channelName+" :Channel name too long")
-				return
-			}
+// joinChannel joins client to channelName, following +f forwards when the
+// join is refused. visited holds the lower-cased names already tried so that
+// forwarding loops end with the original error.
+func (c *JoinCommand) joinChannel(client *server.Client, channelName, key string, visited map[string]bool) {
+	cfg := config.Get()
 
-			channel = c.server.CreateChannel(channelName)
-			isNewChannel = true
-		} else {
-			if channel.HasMode(server.ModeInviteOnly) && !channel.IsInvited(client.Nick) && !channel.IsOperator(client) {
-				client.SendNumeric(utils.ERR_INVITEONLYCHAN, channelName+" :Cannot join channel (+i)")
-				continue
-			}
+	channel := c.server.GetChannel(channelName)
+	isNewChannel := false
 
-			if channel.IsBanned(client) {
-				client.SendNumeric(utils.ERR_BANNEDFROMCHAN, channelName+" :Cannot join channel (+b)")
-				continue
-			}
+	if channel == nil {
+		if len(channelName) > cfg.Security.MaxChannelName {
+			client.SendNumeric(utils.ERR_BADCHANMASK, channelName+" :Channel name too long")
+			return
+		}
 
-			if channel.Key != "" && key != channel.Key && !channel.IsOperator(client) {
-				client.SendNumeric(utils.ERR_BADCHANNELKEY, channelName+" :Cannot join channel (+k)")
-				continue
-			}
+		channel, isNewChannel = c.server.GetOrCreateChannel(channelName)
 
-			if len(channel.GetClients()) >= cfg.Channels.MaxUsersPerChannel {
-				client.SendNumeric(utils.ERR_CHANNELISFULL, channelName+" :Cannot join channel (+l)")
-				continue
+		// A preset channel comes into existence with its key, limit and
+		// bans already in place, so they apply to its first member too.
+		if channel.IsPreset() {
+			if numeric, reason := c.checkAccess(client, channel, key); numeric != 0 {
+				c.server.DestroyChannelIfEmpty(channel)
+				c.refuseJoin(client, channel, numeric, reason, visited)
+				return
 			}
 		}
+	} else {
+		if channel.HasClient(client) {
+			return
+		}
 
+		if numeric, reason := c.checkAccess(client, channel, key); numeric != 0 {
+			c.refuseJoin(client, channel, numeric, reason, visited)
+			return
+		}
+	}
+
+	if !channel.AddClient(client) {
+		// The channel emptied and was destroyed after we looked it up.
+		channel, isNewChannel = c.server.GetOrCreateChannel(channelName)
 		channel.AddClient(client)
+	}
 
-		if isNewChannel {
-			channel.SetOperator(client, true)
+	channel.RecordJoin()
 
-			if cfg.Channels.DefaultModes != "" {
-				channel.ApplyDefaultModes(cfg.Channels.DefaultModes)
+	// Reply with the channel's own name rather than the case it was typed in.
+	channelName = channel.Name
 
-				channel.Broadcast(fmt.Sprintf(":%s MODE %s %s", client.Nick, channelName, cfg.Channels.DefaultModes))
-			}
-		}
+	// Preset channels take their modes from the configuration and only op
+	// users matching their auto-op masks.
+	autoOp := channel.IsPreset() && channel.IsAutoOp(client)
 
-		joinMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.GetHost()) + " JOIN " + channelName
-		channel.Broadcast(joinMsg)
+	if isNewChannel && !channel.IsPreset() {
+		channel.SetOperator(client, true)
 
-		topic, setBy, setAt := channel.GetTopic()
-		if topic != "" {
-			client.SendNumeric(utils.RPL_TOPIC, channelName+" :"+topic)
-			client.SendNumeric(utils.RPL_TOPICWHOTIME, channelName+" "+setBy+" "+fmt.Sprintf("%d", setAt.Unix()))
-		} else {
-			client.SendNumeric(utils.RPL_NOTOPIC, channelName+" :No topic is set")
+		if cfg.Channels.DefaultModes != "" {
+			channel.ApplyDefaultModes(cfg.Channels.DefaultModes)
+
+			channel.Broadcast(fmt.Sprintf(":%s MODE %s %s", client.Nick, channelName, cfg.Channels.DefaultModes))
 		}
+	} else if autoOp {
+		channel.SetOperator(client, true)
+	}
+
+	joinMsg := ":" + client.Prefix() + " JOIN " + channelName
+	channel.Broadcast(joinMsg)
+
+	if autoOp {
+		channel.Broadcast(":" + utils.SERVER_NAME + " MODE " + channelName + " +o " + client.Nick)
+	}
+
+	topic, setBy, setAt := channel.GetTopic()
+	if topic != "" {
+		client.SendNumeric(utils.RPL_TOPIC, channelName+" :"+topic)
+		client.SendNumeric(utils.RPL_TOPICWHOTIME, channelName+" "+setBy+" "+fmt.Sprintf("%d", setAt.Unix()))
+	} else {
+		client.SendNumeric(utils.RPL_NOTOPIC, channelName+" :No topic is set")
+	}
+
+	sendNameReply(client, channel)
+	sendEntryMessage(client, channel)
+
+	if !isFloodExempt(channel, client) && channel.RecordJoinPartFlood(client) {
+		applyFloodAction(c.server, channel, client, server.FloodUser)
+	}
+}
+
+// checkAccess returns the error numeric and reason for refusing client entry
+// to an existing channel, or zero if the join is allowed.
+func (c *JoinCommand) checkAccess(client *server.Client, channel *server.Channel, key string) (int, string) {
+	cfg := config.Get()
 
-		sendNameReply(client, channel)
+	if channel.HasMode(server.ModeInviteOnly) && !channel.IsInvited(client.Nick) && !channel.IsOperator(client) {
+		return utils.ERR_INVITEONLYCHAN, "Cannot join channel (+i)"
 	}
+
+	if channel.IsBanned(client) {
+		return utils.ERR_BANNEDFROMCHAN, "Cannot join channel (+b)"
+	}
+
+	if channel.HasMode(server.ModeRegisteredOnly) && !client.IsLoggedIn() && !channel.IsInvited(client.Nick) {
+		return utils.ERR_NEEDREGGEDNICK, "Cannot join channel (+R) - you need to be logged into your account"
+	}
+
+	if channel.Key != "" && key != channel.Key && !channel.IsOperator(client) {
+		return utils.ERR_BADCHANNELKEY, "Cannot join channel (+k)"
+	}
+
+	userCount := len(channel.GetClients())
+	if userCount >= cfg.Channels.MaxUsersPerChannel || (channel.Limit > 0 && userCount >= channel.Limit) {
+		return utils.ERR_CHANNELISFULL, "Cannot join channel (+l)"
+	}
+
+	if delay := channel.RejoinDelay(client); delay > 0 {
+		seconds := int(math.Ceil(delay.Seconds()))
+		return utils.ERR_DELAYREJOIN, fmt.Sprintf("You must wait %d seconds after being kicked to rejoin (+J)", seconds)
+	}
+
+	if channel.IsJoinThrottled() {
+		return utils.ERR_THROTTLE, "Cannot join channel (+j) - throttle exceeded, try again later"
+	}
+
+	return 0, ""
+}
+
+// refuseJoin either forwards client to the channel's +f target or reports
+// why the join failed.
+func (c *JoinCommand) refuseJoin(client *server.Client, channel *server.Channel, numeric int, reason string, visited map[string]bool) {
+	forward := channel.Forward
+	if forward != "" && numeric != utils.ERR_DELAYREJOIN && !visited[strings.ToLower(forward)] && c.server.GetChannel(forward) != nil {
+		visited[strings.ToLower(forward)] = true
+
+		client.SendNumeric(utils.ERR_LINKCHANNEL, channel.Name+" "+forward+" :Forwarding to another channel")
+		c.joinChannel(client, forward, "", visited)
+		return
+	}
+
+	client.SendNumeric(numeric, channel.Name+" :"+reason)
 }
 
 func (c *JoinCommand) Help() string {
 	return "JOIN <channel>{,<channel>} [<key>{,<key>}] - Joins the specified channels"
 }
 
+// sendEntryMessage shows the channel's entry message, if it has one, as a
+// NOTICE from the server. The message may use the MOTD formatting syntax.
+func sendEntryMessage(client *server.Client, channel *server.Channel) {
+	message := channel.GetEntryMessage()
+	if message == "" {
+		return
+	}
+
+	for _, line := range strings.Split(utils.FormatMOTD(message), "\n") {
+		if line != "" {
+			client.Send(":" + utils.SERVER_NAME + " NOTICE " + client.Nick + " :[" + channel.Name + "] " + line)
+		}
+	}
+}
+
 func sendNameReply(client *server.Client, channel *server.Channel) {
 	var nickList strings.Builder
 
+	isMember := channel.HasClient(client)
+
 	for _, member := range channel.GetClients() {
+		if !isMember && !member.IsVisibleTo(client) {
+			continue
+		}
+
 		if nickList.Len() > 0 {
 			nickList.WriteString(" ")
 		}
@@ -51,6 +51,8 @@ func (c *KickCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
+	channelName = channel.Name
+
 	if !channel.HasClient(client) {
 		client.SendNumeric(utils.ERR_NOTONCHANNEL, channelName+" :You're not on that channel")
 		return
@@ -72,10 +74,11 @@ func (c *KickCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
-	kickMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " KICK " + channelName + " " + targetNick + " :" + reason
+	kickMsg := ":" + client.Prefix() + " KICK " + channelName + " " + targetNick + " :" + reason
 	channel.Broadcast(kickMsg)
 
-	channel.RemoveClient(targetClient)
+	channel.RecordKick(targetClient)
+	c.server.RemoveFromChannel(channel, targetClient)
 }
 
 func (c *KickCommand) Help() string {
@@ -1,12 +1,22 @@
 package commands
 
 import (
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"goircd/server"
 	"goircd/utils"
 )
 
+// LIST replies are sent in batches with a short pause in between so that a
+// large channel list does not flood the client's connection all at once.
+const (
+	listBatchSize  = 50
+	listBatchDelay = 50 * time.Millisecond
+)
+
 type ListCommand struct {
 	server *server.Server
 }
@@ -21,48 +31,193 @@ func (c *ListCommand) Name() string {
 	return "LIST"
 }
 
+// listFilter holds the ELIST conditions of a LIST request. A channel is
+// listed when it matches one of the names or masks (if any were given), none
+// of the negated masks, the topic pattern and every numeric bound.
+type listFilter struct {
+	names         map[string]bool
+	masks         []*utils.HostMatcher
+	excludes      []*utils.HostMatcher
+	topic         *utils.HostMatcher
+	minUsers      int
+	maxUsers      int
+	createdBefore time.Time
+	createdAfter  time.Time
+	topicBefore   time.Time
+	topicAfter    time.Time
+}
+
 func (c *ListCommand) Execute(client *server.Client, params string) {
 	if !client.IsRegistered() {
 		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
 		return
 	}
 
+	filter, ok := parseListFilter(strings.Fields(params))
+	if !ok {
+		client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "LIST :Invalid LIST filter")
+		return
+	}
+
+	if !client.BeginList() {
+		client.SendNumeric(utils.RPL_TRYAGAIN, "LIST :Please wait a while and try again.")
+		return
+	}
+
+	var lines []string
+	for _, channel := range c.server.GetAllChannels() {
+		if channel.HasMode(server.ModeSecret) && !channel.HasClient(client) {
+			continue
+		}
+
+		userCount := len(channel.GetClients())
+		topic, _, topicSetAt := channel.GetTopic()
+
+		if !filter.matches(channel, userCount, topic, topicSetAt) {
+			continue
+		}
+
+		lines = append(lines, channel.Name+" "+strconv.Itoa(userCount)+" :"+topic)
+	}
+	sort.Strings(lines)
+
 	client.SendNumeric(utils.RPL_LISTSTART, "Channel :Users  Name")
 
-	channels := c.server.GetAllChannels()
+	go func() {
+		defer client.EndList()
 
-	if params != "" {
-		channelNames := strings.Split(params, ",")
-		filteredChannels := make([]*server.Channel, 0)
+		for start := 0; start < len(lines); start += listBatchSize {
+			if start > 0 {
+				time.Sleep(listBatchDelay)
+			}
 
-		for _, channelName := range channelNames {
-			channelName = strings.TrimSpace(channelName)
-			if channel := c.server.GetChannel(channelName); channel != nil {
-				filteredChannels = append(filteredChannels, channel)
+			for _, line := range lines[start:min(start+listBatchSize, len(lines))] {
+				client.SendNumeric(utils.RPL_LIST, line)
 			}
 		}
-		channels = filteredChannels
+
+		client.SendNumeric(utils.RPL_LISTEND, ":End of LIST")
+	}()
+}
+
+// parseListFilter understands the ELIST conditions advertised in ISUPPORT:
+// channel names and masks (M), !masks (N), >n and <n user counts (U), and
+// C>n, C<n, T>n and T<n for channel and topic age in minutes (C, T). T:glob
+// additionally matches against the topic text.
+func parseListFilter(args []string) (*listFilter, bool) {
+	filter := &listFilter{
+		names:    make(map[string]bool),
+		minUsers: -1,
+		maxUsers: -1,
 	}
 
-	for _, channel := range channels {
-		if channel.HasMode(server.ModeSecret) && !channel.HasClient(client) {
+	if len(args) == 0 {
+		return filter, true
+	}
+
+	now := time.Now()
+
+	for _, item := range strings.Split(args[0], ",") {
+		if item == "" {
 			continue
 		}
 
-		userCount := len(channel.GetClients())
-		topic, _, _ := channel.GetTopic()
+		switch {
+		case strings.HasPrefix(item, "T:"):
+			filter.topic = utils.NewHostMatcher(item[2:])
 
-		listLine := channel.Name + " " + string(rune(userCount+'0'))
-		if topic != "" {
-			listLine += " :" + topic
+		case strings.HasPrefix(item, "C>"), strings.HasPrefix(item, "C<"),
+			strings.HasPrefix(item, "T>"), strings.HasPrefix(item, "T<"):
+			minutes, err := strconv.Atoi(item[2:])
+			if err != nil || minutes < 0 {
+				return nil, false
+			}
+
+			cutoff := now.Add(-time.Duration(minutes) * time.Minute)
+			switch item[:2] {
+			case "C>":
+				filter.createdBefore = cutoff
+			case "C<":
+				filter.createdAfter = cutoff
+			case "T>":
+				filter.topicBefore = cutoff
+			case "T<":
+				filter.topicAfter = cutoff
+			}
+
+		case item[0] == '>' || item[0] == '<':
+			count, err := strconv.Atoi(item[1:])
+			if err != nil || count < 0 {
+				return nil, false
+			}
+
+			if item[0] == '>' {
+				filter.minUsers = count
+			} else {
+				filter.maxUsers = count
+			}
+
+		case item[0] == '!':
+			filter.excludes = append(filter.excludes, utils.NewHostMatcher(item[1:]))
+
+		case strings.ContainsAny(item, "*?"):
+			filter.masks = append(filter.masks, utils.NewHostMatcher(item))
+
+		default:
+			filter.names[strings.ToLower(item)] = true
 		}
+	}
 
-		client.SendNumeric(utils.RPL_LIST, listLine)
+	return filter, true
+}
+
+func (f *listFilter) matches(channel *server.Channel, userCount int, topic string, topicSetAt time.Time) bool {
+	if len(f.names) > 0 || len(f.masks) > 0 {
+		found := f.names[strings.ToLower(channel.Name)]
+		for _, mask := range f.masks {
+			found = found || mask.Matches(channel.Name)
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, mask := range f.excludes {
+		if mask.Matches(channel.Name) {
+			return false
+		}
+	}
+
+	if f.topic != nil && !f.topic.Matches(topic) {
+		return false
+	}
+
+	if f.minUsers >= 0 && userCount <= f.minUsers {
+		return false
+	}
+	if f.maxUsers >= 0 && userCount >= f.maxUsers {
+		return false
+	}
+
+	createdAt := channel.CreatedAt()
+	if !f.createdBefore.IsZero() && !createdAt.Before(f.createdBefore) {
+		return false
+	}
+	if !f.createdAfter.IsZero() && !createdAt.After(f.createdAfter) {
+		return false
+	}
+
+	if !f.topicBefore.IsZero() && (topic == "" || !topicSetAt.Before(f.topicBefore)) {
+		return false
+	}
+	if !f.topicAfter.IsZero() && (topic == "" || !topicSetAt.After(f.topicAfter)) {
+		return false
 	}
 
-	client.SendNumeric(utils.RPL_LISTEND, ":End of LIST")
+	return true
 }
 
 func (c *ListCommand) Help() string {
-	return "LIST [<channels>] - List channels on the server"
+	return "LIST [<channel|mask|!mask|>n|<n|C>n|C<n|T>n|T<n|T:topic>{,...}] - List channels on the server, " +
+		"optionally filtered by name, user count, channel age, topic age (in minutes) or topic text"
 }
@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"strings"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+// messageSender delivers PRIVMSG, NOTICE and TAGMSG, which share target
+// resolution and channel restrictions. A NOTICE must never trigger an
+// automatic reply, so it is dropped silently wherever a PRIVMSG would get an
+// error numeric. A TAGMSG carries only tags, so it only reaches clients with
+// the message-tags capability and is not kept in history.
+type messageSender struct {
+	server  *server.Server
+	client  *server.Client
+	command string
+	// tags holds the client-only tags sent with the message.
+	tags map[string]string
+}
+
+func (m *messageSender) isNotice() bool {
+	return m.command == "NOTICE"
+}
+
+func (m *messageSender) isTagMsg() bool {
+	return m.command == "TAGMSG"
+}
+
+// send delivers a message to recipient with the entry's tags and the
+// sender's client-only tags.
+func (m *messageSender) send(recipient *server.Client, entry server.HistoryEntry, target string) {
+	if m.isTagMsg() && !recipient.HasCap(server.CapMessageTags) {
+		return
+	}
+
+	tags := entry.Tags()
+	for key, value := range m.tags {
+		tags[key] = value
+	}
+
+	line := ":" + entry.Source + " " + m.command + " " + target
+	if !m.isTagMsg() {
+		line += " :" + entry.Text
+	}
+	recipient.SendTagged(tags, line)
+}
+
+func (m *messageSender) sendError(numeric int, text string) {
+	if !m.isNotice() {
+		m.client.SendNumeric(numeric, text)
+	}
+}
+
+// execute handles the parameters of a PRIVMSG or NOTICE command.
+func (m *messageSender) execute(params string) {
+	if !m.client.IsRegistered() {
+		m.sendError(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	if params == "" {
+		m.sendError(utils.ERR_NORECIPIENT, ":No recipient given ("+m.command+")")
+		return
+	}
+
+	parts := strings.SplitN(params, " ", 2)
+
+	var message string
+	if !m.isTagMsg() {
+		if len(parts) < 2 {
+			m.sendError(utils.ERR_NOTEXTTOSEND, ":No text to send")
+			return
+		}
+
+		message = strings.TrimPrefix(parts[1], ":")
+		if message == "" {
+			m.sendError(utils.ERR_NOTEXTTOSEND, ":No text to send")
+			return
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, target := range strings.Split(parts[0], ",") {
+		key := strings.ToLower(target)
+		if target == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if len(seen) > utils.MAX_MESSAGE_TARGETS {
+			m.sendError(utils.ERR_TOOMANYTARGETS, target+" :Too many targets. Message not delivered")
+			return
+		}
+
+		m.deliver(target, message)
+	}
+}
+
+func (m *messageSender) deliver(target, message string) {
+	_, channelName := utils.SplitStatusTarget(target)
+
+	switch {
+	case strings.HasPrefix(target, "$"):
+		m.deliverToServer(target, message)
+	case strings.HasPrefix(channelName, "#") || strings.HasPrefix(channelName, "&"):
+		m.deliverToChannel(target, message)
+	default:
+		m.deliverToClient(target, message)
+	}
+}
+
+// deliverToServer sends a message to every user on a server whose name
+// matches the $mask target, which only IRC operators may use.
+func (m *messageSender) deliverToServer(target, message string) {
+	if !m.client.IsOperator() {
+		m.sendError(utils.ERR_NOPRIVILEGES, ":Permission Denied- You're not an IRC operator")
+		return
+	}
+
+	mask := target[1:]
+	if mask == "" || !utils.MatchesHost(utils.SERVER_NAME, mask) {
+		m.sendError(utils.ERR_NOSUCHSERVER, mask+" :No such server")
+		return
+	}
+
+	entry := server.NewHistoryEntry(m.client, m.command, target, message)
+	for _, recipient := range m.server.GetAllClients() {
+		if recipient != m.client && recipient.IsRegistered() {
+			m.send(recipient, entry, target)
+		}
+	}
+}
+
+func (m *messageSender) deliverToChannel(target, message string) {
+	client := m.client
+	status, channelName := utils.SplitStatusTarget(target)
+
+	channel := m.server.GetChannel(channelName)
+	if channel == nil {
+		m.sendError(utils.ERR_NOSUCHCHANNEL, channelName+" :No such channel")
+		return
+	}
+
+	target = channel.Name
+	if status != 0 {
+		target = string(status) + channel.Name
+	}
+
+	if !channel.HasClient(client) && channel.HasMode(server.ModeNoExternalMessages) {
+		m.sendError(utils.ERR_CANNOTSENDTOCHAN, target+" :Cannot send to channel")
+		return
+	}
+
+	if channel.HasMode(server.ModeModerated) && !channel.IsVoiced(client) && !channel.IsOperator(client) {
+		m.sendError(utils.ERR_CANNOTSENDTOCHAN, target+" :Cannot send to channel (+m)")
+		return
+	}
+
+	if channel.IsQuieted(client) && !channel.IsVoiced(client) && !channel.IsOperator(client) {
+		m.sendError(utils.ERR_CANNOTSENDTOCHAN, target+" :Cannot send to channel (+q)")
+		return
+	}
+
+	if channel.HasMode(server.ModeModerateUnregistered) && !client.IsLoggedIn() && !channel.IsVoiced(client) && !channel.IsOperator(client) {
+		m.sendError(utils.ERR_CANNOTSENDTOCHAN, target+" :Cannot send to channel (+M) - you need to be logged into your account")
+		return
+	}
+
+	if channel.HasMode(server.ModeNoCTCP) && utils.IsCTCP(message) && utils.CTCPCommand(message) != "ACTION" {
+		m.sendError(utils.ERR_CANNOTSENDTOCHAN, target+" :Cannot send to channel (+C)")
+		return
+	}
+
+	if channel.HasMode(server.ModeNoColour) && utils.HasFormatting(message) {
+		m.sendError(utils.ERR_CANNOTSENDTOCHAN, target+" :Cannot send to channel (+c)")
+		return
+	}
+
+	if channel.HasMode(server.ModeStripColour) && !m.isTagMsg() {
+		message = utils.StripFormatting(message)
+		if message == "" {
+			m.sendError(utils.ERR_NOTEXTTOSEND, ":No text to send")
+			return
+		}
+	}
+
+	// Tag-only messages such as typing notifications have no text to
+	// repeat and are not counted towards +F.
+	if !m.isTagMsg() && !isFloodExempt(channel, client) {
+		if violation := channel.RecordMessageFlood(client, message); violation != server.FloodNone {
+			applyFloodAction(m.server, channel, client, violation)
+			return
+		}
+	}
+
+	entry := server.NewHistoryEntry(client, m.command, channel.Name, message)
+	for _, recipient := range channel.MessageRecipients(client, status) {
+		m.send(recipient, entry, target)
+	}
+
+	// Status messages reach only part of the channel, so they are not
+	// kept in the channel's history.
+	if status != 0 || m.isTagMsg() {
+		return
+	}
+
+	if history := m.server.History(); history != nil {
+		history.Add(server.ChannelHistoryKey(channel.Name), entry)
+	}
+}
+
+func (m *messageSender) deliverToClient(target, message string) {
+	client := m.client
+
+	targetClient := m.server.FindClient(target)
+	if targetClient == nil {
+		m.sendError(utils.ERR_NOSUCHNICK, target+" :No such nick/channel")
+		return
+	}
+
+	if targetClient.HasUserMode(utils.USER_MODE_REGPM) && !client.IsLoggedIn() && !client.IsOperator() {
+		m.sendError(utils.ERR_NONONREG, targetClient.Nick+" :You must log in to message this user")
+		return
+	}
+
+	// Silenced senders are dropped without telling them.
+	if targetClient.IsSilencing(client) {
+		return
+	}
+
+	if !targetClient.AcceptsMessagesFrom(client) {
+		if m.isNotice() || m.isTagMsg() {
+			return
+		}
+
+		client.SendNumeric(utils.RPL_TARGUMODEG, targetClient.Nick+" :is in +g mode (server-side ignore)")
+
+		if targetClient.ShouldNotifyCallerID() {
+			targetClient.SendNumeric(utils.RPL_UMODEGMSG, client.Nick+" "+client.User+"@"+client.HostFor(targetClient)+" :is messaging you, and you have umode +g")
+			client.SendNumeric(utils.RPL_TARGNOTIFY, targetClient.Nick+" :has been informed that you messaged them")
+		}
+		return
+	}
+
+	entry := server.NewHistoryEntry(client, m.command, targetClient.Nick, message)
+	m.send(targetClient, entry, targetClient.Nick)
+}
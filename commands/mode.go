@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -51,211 +50,103 @@ func (c *ModeCommand) handleChannelMode(client *server.Client, channelName strin
 		return
 	}
 
-	if len(parts) == 1 {
-		c.showChannelModes(client, channel)
-		return
-	}
-
-	modeString := parts[1]
-	modeParams := ""
-	if strings.Contains(modeString, " ") {
-		modeParts := strings.SplitN(modeString, " ", 2)
-		modeString = modeParts[0]
-		modeParams = modeParts[1]
-	}
-
-	if (modeString == "+b" || modeString == "b") && modeParams == "" {
+	channelName = channel.Name
 
-		if !channel.HasClient(client) && !client.IsOperator() {
-			client.SendNumeric(utils.ERR_NOTONCHANNEL, channelName+" :You're not on that channel")
-			return
-		}
-		c.showBanList(client, channel)
+	fields := strings.Fields(strings.Join(parts[1:], " "))
+	if len(fields) == 0 {
+		c.showChannelModes(client, channel)
 		return
 	}
 
-	if !channel.IsOperator(client) {
-		client.SendNumeric(utils.ERR_CHANOPRIVSNEEDED, channelName+" :You're not channel operator")
-		return
-	}
+	modes := utils.ParseModeString(fields[0], fields[1:], func(letter rune, adding bool) bool {
+		def := server.GetChannelMode(letter)
+		return def != nil && def.TakesParam(adding)
+	}, utils.MAX_MODE_PARAMS)
 
-	adding := true
-	paramIndex := 0
-	paramList := strings.Split(modeParams, " ")
-	var modeChanges strings.Builder
-	var appliedModes strings.Builder
-	var appliedParams []string
+	var applied []utils.Mode
+	deniedOp := false
 
-	for _, char := range modeString {
-		if char == '+' {
-			adding = true
-			continue
-		}
-		if char == '-' {
-			adding = false
+	for _, mode := range modes {
+		def := server.GetChannelMode(mode.Letter)
+		if def == nil {
+			client.SendNumeric(utils.ERR_UNKNOWNMODE, string(mode.Letter)+" :is unknown mode char to me for "+channelName)
 			continue
 		}
 
-		switch char {
-		case 'o':
-			if paramIndex >= len(paramList) {
-				continue
-			}
-			targetNick := paramList[paramIndex]
-			paramIndex++
-
-			targetClient := c.server.GetClient(targetNick)
-			if targetClient == nil {
-				client.SendNumeric(utils.ERR_NOSUCHNICK, targetNick+" :No such nick/channel")
+		// A list mode without a mask is a query, which any member may make.
+		if def.Type == server.ModeTypeList && mode.Param == "" {
+			if !mode.Adding {
 				continue
 			}
-
-			if !channel.HasClient(targetClient) {
-				client.SendNumeric(utils.ERR_USERNOTINCHANNEL, targetNick+" "+channelName+" :They aren't on that channel")
+			if !channel.HasClient(client) && !client.IsOperator() {
+				client.SendNumeric(utils.ERR_NOTONCHANNEL, channelName+" :You're not on that channel")
 				continue
 			}
+			c.showList(client, channel, mode.Letter)
+			continue
+		}
 
-			channel.SetOperator(targetClient, adding)
-			if adding {
-				modeChanges.WriteString(fmt.Sprintf("+%c", char))
-			} else {
-				modeChanges.WriteString(fmt.Sprintf("-%c", char))
-			}
-			appliedModes.WriteString(string(char))
-			appliedParams = append(appliedParams, targetNick)
-		case 'v':
-			if paramIndex >= len(paramList) {
-				continue
+		if !channel.IsOperator(client) {
+			if !deniedOp {
+				client.SendNumeric(utils.ERR_CHANOPRIVSNEEDED, channelName+" :You're not channel operator")
+				deniedOp = true
 			}
-			targetNick := paramList[paramIndex]
-			paramIndex++
+			continue
+		}
 
-			targetClient := c.server.GetClient(targetNick)
-			if targetClient == nil {
-				client.SendNumeric(utils.ERR_NOSUCHNICK, targetNick+" :No such nick/channel")
-				continue
-			}
+		if def.OperOnly && !client.IsOperator() {
+			client.SendNumeric(utils.ERR_NOPRIVILEGES, ":Permission Denied- You're not an IRC operator")
+			continue
+		}
 
-			if !channel.HasClient(targetClient) {
-				client.SendNumeric(utils.ERR_USERNOTINCHANNEL, targetNick+" "+channelName+" :They aren't on that channel")
-				continue
-			}
+		// Keys may be removed without repeating them; every other mode
+		// that takes a parameter needs one.
+		if def.TakesParam(mode.Adding) && mode.Param == "" && (mode.Adding || def.Type != server.ModeTypeSetting) {
+			client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "MODE :Not enough parameters")
+			continue
+		}
 
-			channel.SetVoiced(targetClient, adding)
-			if adding {
-				modeChanges.WriteString(fmt.Sprintf("+%c", char))
-			} else {
-				modeChanges.WriteString(fmt.Sprintf("-%c", char))
-			}
-			appliedModes.WriteString(string(char))
-			appliedParams = append(appliedParams, targetNick)
-		case 'b':
-			if paramIndex >= len(paramList) {
+		change := &server.ModeChange{
+			Server:  c.server,
+			Channel: channel,
+			Client:  client,
+			Adding:  mode.Adding,
+			Param:   mode.Param,
+		}
 
-				if adding {
-					c.showBanList(client, channel)
+		if def.Validate != nil {
+			if err := def.Validate(change); err != nil {
+				if modeErr, ok := err.(*server.ModeError); ok {
+					client.SendNumeric(modeErr.Numeric, modeErr.Text)
+				} else {
+					client.SendNumeric(utils.ERR_UNKNOWNMODE, string(mode.Letter)+" :"+err.Error())
 				}
 				continue
 			}
-			mask := paramList[paramIndex]
-			paramIndex++
-
-			channel.SetBanned(mask, adding)
-			if adding {
-				modeChanges.WriteString(fmt.Sprintf("+%c", char))
-			} else {
-				modeChanges.WriteString(fmt.Sprintf("-%c", char))
-			}
-			appliedModes.WriteString(string(char))
-			appliedParams = append(appliedParams, mask)
-		case 'k':
-			if adding {
-				if paramIndex >= len(paramList) {
-					client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "MODE :Not enough parameters")
-					continue
-				}
-				key := paramList[paramIndex]
-				paramIndex++
-
-				if channel.Key != "" {
-					client.SendNumeric(utils.ERR_KEYSET, channelName+" :Channel key already set")
-					continue
-				}
-
-				channel.Key = key
-				modeChanges.WriteString(fmt.Sprintf("+%c", char))
-				appliedModes.WriteString(string(char))
-				appliedParams = append(appliedParams, key)
-			} else {
-				channel.Key = ""
-				modeChanges.WriteString(fmt.Sprintf("-%c", char))
-				appliedModes.WriteString(string(char))
-			}
-		case 'l':
-			if adding {
-				if paramIndex >= len(paramList) {
-					client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "MODE :Not enough parameters")
-					continue
-				}
-				limitStr := paramList[paramIndex]
-				paramIndex++
-
-				limit, err := strconv.Atoi(limitStr)
-				if err != nil || limit < 0 {
-					client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "MODE :Invalid limit")
-					continue
-				}
-
-				channel.Limit = limit
-				modeChanges.WriteString(fmt.Sprintf("+%c", char))
-				appliedModes.WriteString(string(char))
-				appliedParams = append(appliedParams, limitStr)
-			} else {
-				channel.Limit = 0
-				modeChanges.WriteString(fmt.Sprintf("-%c", char))
-				appliedModes.WriteString(string(char))
-			}
-
-		case 'i', 'm', 'n', 'p', 's', 't':
-			var mode server.ChannelMode
-
-			switch char {
-			case 'i':
-				mode = server.ModeInviteOnly
-			case 'm':
-				mode = server.ModeModerated
-			case 'n':
-				mode = server.ModeNoExternalMessages
-			case 'p':
-				mode = server.ModePrivate
-			case 's':
-				mode = server.ModeSecret
-			case 't':
-				mode = server.ModeTopicSettableByOpsOnly
-			}
-
-			channel.SetMode(mode, adding)
-			if adding {
-				modeChanges.WriteString(fmt.Sprintf("+%c", char))
-			} else {
-				modeChanges.WriteString(fmt.Sprintf("-%c", char))
-			}
-			appliedModes.WriteString(string(char))
-
-		default:
-			client.SendNumeric(utils.ERR_UNKNOWNMODE, string(char)+" :is unknown mode char to me for "+channelName)
 		}
-	}
 
-	if modeChanges.Len() > 0 {
-		modeMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " MODE " + channelName + " " + modeChanges.String()
+		var changed bool
+		if mode.Adding {
+			changed = def.Apply(change)
+		} else {
+			changed = def.Unapply(change)
+		}
+		if !changed {
+			continue
+		}
 
-		if len(appliedParams) > 0 {
-			modeMsg += " " + strings.Join(appliedParams, " ")
+		mode.Param = ""
+		if def.AnnouncesParam(mode.Adding) {
+			mode.Param = change.Param
 		}
+		applied = append(applied, mode)
+	}
 
-		channel.Broadcast(modeMsg)
+	if len(applied) > 0 {
+		channel.Broadcast(":" + client.Prefix() + " MODE " + channelName + " " + utils.FormatModeString(applied))
 	}
+
+	c.server.DestroyChannelIfEmpty(channel)
 }
 
 func (c *ModeCommand) handleUserMode(client *server.Client, target string, parts []string) {
@@ -264,54 +155,46 @@ func (c *ModeCommand) handleUserMode(client *server.Client, target string, parts
 		return
 	}
 
-	if len(parts) == 1 {
+	fields := strings.Fields(strings.Join(parts[1:], " "))
+	if len(fields) == 0 {
 		c.showUserModes(client)
 		return
 	}
 
-	modeString := parts[1]
-
-	adding := true
-	var modeChanges strings.Builder
+	modes := utils.ParseModeString(fields[0], fields[1:], func(rune, bool) bool {
+		return false
+	}, utils.MAX_MODE_PARAMS)
 
-	for _, char := range modeString {
-		if char == '+' {
-			adding = true
-			continue
-		}
-		if char == '-' {
-			adding = false
-			continue
-		}
+	var applied []utils.Mode
+	unknownSent := false
 
-		switch char {
-		case 'i':
-			if adding {
-				modeChanges.WriteString(fmt.Sprintf("+%c", char))
-			} else {
-				modeChanges.WriteString(fmt.Sprintf("-%c", char))
-			}
-		case 'w':
-			if adding {
-				modeChanges.WriteString(fmt.Sprintf("+%c", char))
-			} else {
-				modeChanges.WriteString(fmt.Sprintf("-%c", char))
-			}
+	for _, mode := range modes {
+		switch mode.Letter {
+		case 'B', 'D', 'g', 'i', 'R', 'w':
+			client.SetUserMode(mode.Letter, mode.Adding)
 		case 'o':
-			if adding {
+			if mode.Adding {
 				client.SendNumeric(utils.ERR_NOPRIVILEGES, ":Permission Denied- You're not an IRC operator")
 				continue
 			}
 
+			// OPER is the only way to log in, so dropping operator
+			// status logs the client out as well.
 			client.SetOperator(false)
-			modeChanges.WriteString(fmt.Sprintf("-%c", char))
+			client.SetAccount("")
 		default:
-			client.SendNumeric(utils.ERR_UMODEUNKNOWNFLAG, ":Unknown MODE flag")
+			if !unknownSent {
+				client.SendNumeric(utils.ERR_UMODEUNKNOWNFLAG, ":Unknown MODE flag")
+				unknownSent = true
+			}
+			continue
 		}
+
+		applied = append(applied, mode)
 	}
 
-	if modeChanges.Len() > 0 {
-		client.SendMessage(utils.FormatUserMask(client.Nick, client.User, client.Host), "MODE", client.Nick, modeChanges.String())
+	if len(applied) > 0 {
+		client.SendMessage(client.Prefix(), "MODE", client.Nick, utils.FormatModeString(applied))
 	}
 }
 
@@ -319,32 +202,20 @@ func (c *ModeCommand) showChannelModes(client *server.Client, channel *server.Ch
 	var modes strings.Builder
 	var params []string
 
-	if channel.HasMode(server.ModeInviteOnly) {
-		modes.WriteString("i")
-	}
-	if channel.HasMode(server.ModeModerated) {
-		modes.WriteString("m")
-	}
-	if channel.HasMode(server.ModeNoExternalMessages) {
-		modes.WriteString("n")
-	}
-	if channel.HasMode(server.ModePrivate) {
-		modes.WriteString("p")
-	}
-	if channel.HasMode(server.ModeSecret) {
-		modes.WriteString("s")
-	}
-	if channel.HasMode(server.ModeTopicSettableByOpsOnly) {
-		modes.WriteString("t")
-	}
+	for _, def := range server.ChannelModeDefs() {
+		if def.Value == nil {
+			continue
+		}
 
-	if channel.Key != "" {
-		modes.WriteString("k")
-		params = append(params, channel.Key)
-	}
-	if channel.Limit > 0 {
-		modes.WriteString("l")
-		params = append(params, strconv.Itoa(channel.Limit))
+		param, set := def.Value(channel)
+		if !set {
+			continue
+		}
+
+		modes.WriteRune(def.Letter)
+		if def.Type != server.ModeTypeFlag {
+			params = append(params, param)
+		}
 	}
 
 	modeString := "+"
@@ -357,34 +228,44 @@ func (c *ModeCommand) showChannelModes(client *server.Client, channel *server.Ch
 	} else {
 		client.SendNumeric(utils.RPL_CHANNELMODEIS, channel.Name+" "+modeString)
 	}
-}
-
-func (c *ModeCommand) showBanList(client *server.Client, channel *server.Channel) {
-	banList := channel.GetBanList()
-
-	for _, banMask := range banList {
-		client.SendNumeric(utils.RPL_BANLIST, channel.Name+" "+banMask)
-	}
 
-	client.SendNumeric(utils.RPL_ENDOFBANLIST, channel.Name+" :End of channel ban list")
+	client.SendNumeric(utils.RPL_CREATIONTIME, channel.Name+" "+strconv.FormatInt(channel.CreatedAt().Unix(), 10))
 }
 
-func (c *ModeCommand) showUserModes(client *server.Client) {
-	var modes strings.Builder
-
-	if client.IsOperator() {
-		modes.WriteString("o")
+func (c *ModeCommand) showList(client *server.Client, channel *server.Channel, mode rune) {
+	switch mode {
+	case 'b':
+		for _, entry := range channel.GetBanList() {
+			client.SendNumeric(utils.RPL_BANLIST, channel.Name+" "+formatListEntry(entry))
+		}
+		client.SendNumeric(utils.RPL_ENDOFBANLIST, channel.Name+" :End of channel ban list")
+	case 'e':
+		for _, entry := range channel.GetExceptionList() {
+			client.SendNumeric(utils.RPL_EXCEPTLIST, channel.Name+" "+formatListEntry(entry))
+		}
+		client.SendNumeric(utils.RPL_ENDOFEXCEPTLIST, channel.Name+" :End of channel exception list")
+	case 'q':
+		for _, entry := range channel.GetQuietList() {
+			client.SendNumeric(utils.RPL_QUIETLIST, channel.Name+" q "+formatListEntry(entry))
+		}
+		client.SendNumeric(utils.RPL_ENDOFQUIETLIST, channel.Name+" q :End of channel quiet list")
 	}
+}
 
-	modeString := "+"
-	if modes.Len() > 0 {
-		modeString += modes.String()
+func formatListEntry(entry server.ListEntry) string {
+	setBy := entry.SetBy
+	if setBy == "" {
+		setBy = utils.SERVER_NAME
 	}
+	return entry.Mask + " " + setBy + " " + strconv.FormatInt(entry.SetAt.Unix(), 10)
+}
 
-	client.SendNumeric(utils.RPL_UMODEIS, modeString)
+func (c *ModeCommand) showUserModes(client *server.Client) {
+	client.SendNumeric(utils.RPL_UMODEIS, "+"+client.GetUserModes())
 }
 
 func (c *ModeCommand) Help() string {
 	return "MODE <channel> [<mode> [<mode parameters>]] - Changes or displays channel modes\n" +
-		"MODE <nickname> [<mode>] - Changes or displays user modes"
+		"MODE <nickname> [<mode>] - Changes or displays user modes\n" +
+		"Channel modes +R and +M and user mode +R admit only logged-in users; OPER is currently the only way to log in"
 }
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"goircd/config"
+	"goircd/server"
+	"goircd/utils"
+)
+
+type MonitorCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("MONITOR", func(s *server.Server) server.Command {
+		return &MonitorCommand{server: s}
+	})
+}
+
+func (c *MonitorCommand) Name() string {
+	return "MONITOR"
+}
+
+func (c *MonitorCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	parts := strings.Fields(params)
+	if len(parts) == 0 {
+		client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "MONITOR :Not enough parameters")
+		return
+	}
+
+	var targets []string
+	if len(parts) > 1 {
+		for _, target := range strings.Split(parts[1], ",") {
+			if target != "" {
+				targets = append(targets, target)
+			}
+		}
+	}
+
+	switch strings.ToUpper(parts[0]) {
+	case "+":
+		if len(targets) == 0 {
+			client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "MONITOR :Not enough parameters")
+			return
+		}
+
+		limit := config.Get().Users.MonitorLimit
+		var added []string
+		for i, target := range targets {
+			if !utils.IsValidNickname(target) {
+				continue
+			}
+			if !c.server.AddMonitor(client, target, limit) {
+				client.SendNumeric(utils.ERR_MONLISTFULL, strconv.Itoa(limit)+" "+strings.Join(targets[i:], ",")+" :Monitor list is full.")
+				break
+			}
+			added = append(added, target)
+		}
+		c.server.SendMonitorStatus(client, added)
+
+	case "-":
+		for _, target := range targets {
+			c.server.RemoveMonitor(client, target)
+		}
+
+	case "C":
+		c.server.ClearMonitor(client)
+
+	case "L":
+		server.SendTargetList(client, utils.RPL_MONLIST, client.GetMonitorList())
+		client.SendNumeric(utils.RPL_ENDOFMONLIST, ":End of MONITOR list")
+
+	case "S":
+		c.server.SendMonitorStatus(client, client.GetMonitorList())
+
+	default:
+		client.SendNumeric(utils.ERR_UNKNOWNCOMMAND, "MONITOR :Unknown MONITOR subcommand")
+	}
+}
+
+func (c *MonitorCommand) Help() string {
+	return "MONITOR {+|-} <nick>{,<nick>} | MONITOR {C|L|S} - Tracks when the given nicknames come online or go offline"
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"strings"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+type NamesCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("NAMES", func(s *server.Server) server.Command {
+		return &NamesCommand{server: s}
+	})
+}
+
+func (c *NamesCommand) Name() string {
+	return "NAMES"
+}
+
+func (c *NamesCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	if params == "" {
+		for _, channel := range client.GetChannels() {
+			sendNameReply(client, channel)
+		}
+		return
+	}
+
+	channelNames := strings.Split(strings.SplitN(params, " ", 2)[0], ",")
+	for _, channelName := range channelNames {
+		channel := c.server.GetChannel(channelName)
+
+		// Secret channels are hidden from non-members as if they did not exist.
+		if channel == nil || (channel.HasMode(server.ModeSecret) && !channel.HasClient(client)) {
+			client.SendNumeric(utils.RPL_ENDOFNAMES, channelName+" :End of NAMES list")
+			continue
+		}
+
+		sendNameReply(client, channel)
+	}
+}
+
+func (c *NamesCommand) Help() string {
+	return "NAMES [<channel>{,<channel>}] - Lists the visible members of the given channels"
+}
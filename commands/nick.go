@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"strconv"
 	"strings"
 
 	"goircd/server"
@@ -40,14 +41,55 @@ func (c *NickCommand) Execute(client *server.Client, params string) {
 	}
 
 	oldNick := client.Nick
+	if nickname == oldNick {
+		return
+	}
 
-	client.SetNick(nickname)
+	isNickChange := oldNick != "" && client.IsRegistered()
+
+	if isNickChange {
+		for _, channel := range client.GetChannels() {
+			if channel.IsNickChangeLocked() && !isFloodExempt(channel, client) {
+				client.SendNumeric(utils.ERR_NONICKCHANGE, nickname+" :Cannot change nickname while on "+channel.Name+" (+F)")
+				return
+			}
+		}
+	}
 
-	c.server.AddClient(client)
+	oldMask := client.Prefix()
+
+	c.server.ChangeNick(client, nickname)
+
+	if isNickChange {
+		c.announceNickChange(client, oldMask, nickname)
+	}
 
 	if oldNick == "" && client.User != "" && !client.IsRegistered() {
-		client.SetRegistered()
-		server.SendWelcomeMessages(client)
+		c.server.CompleteRegistration(client)
+	}
+}
+
+// announceNickChange tells the client and everyone sharing a channel with
+// them about the new nickname, then accounts the change against +F.
+func (c *NickCommand) announceNickChange(client *server.Client, oldMask, nickname string) {
+	nickMsg := ":" + oldMask + " NICK :" + nickname
+
+	notified := map[*server.Client]bool{client: true}
+	client.Send(nickMsg)
+
+	for _, channel := range client.GetChannels() {
+		for _, member := range channel.GetClients() {
+			if !notified[member] {
+				member.Send(nickMsg)
+				notified[member] = true
+			}
+		}
+
+		if !isFloodExempt(channel, client) && channel.RecordNickChangeFlood() {
+			settings := channel.GetFloodSettings()
+			channel.Broadcast(":" + utils.SERVER_NAME + " NOTICE " + channel.Name + " :Nick change flood detected, nick changes are locked for " +
+				strconv.Itoa(int(settings.Expire.Seconds())) + " seconds")
+		}
 	}
 }
 
@@ -0,0 +1,30 @@
+package commands
+
+import "goircd/server"
+
+type NoticeCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("NOTICE", func(s *server.Server) server.Command {
+		return &NoticeCommand{server: s}
+	})
+}
+
+func (c *NoticeCommand) Name() string {
+	return "NOTICE"
+}
+
+func (c *NoticeCommand) Execute(client *server.Client, params string) {
+	c.ExecuteTagged(client, nil, params)
+}
+
+func (c *NoticeCommand) ExecuteTagged(client *server.Client, tags map[string]string, params string) {
+	sender := &messageSender{server: c.server, client: client, command: "NOTICE", tags: tags}
+	sender.execute(params)
+}
+
+func (c *NoticeCommand) Help() string {
+	return "NOTICE <target>{,<target>} :<message> - Sends a notice to users or channels; no error or automatic reply is ever sent back"
+}
@@ -51,16 +51,16 @@ func (c *KillCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
-	killMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " KILL " + targetNick + " :" + reason
+	killMsg := ":" + client.Prefix() + " KILL " + targetNick + " :" + reason
 	targetClient.Send(killMsg)
 
-	quitMsg := ":" + utils.FormatUserMask(targetClient.Nick, targetClient.User, targetClient.Host) + " QUIT :Killed by " + client.Nick + " (" + reason + ")"
+	quitMsg := ":" + targetClient.Prefix() + " QUIT :Killed by " + client.Nick + " (" + reason + ")"
 	for _, channel := range targetClient.GetChannels() {
 		channel.BroadcastFrom(targetClient, quitMsg)
 	}
 
 	for _, channel := range targetClient.GetChannels() {
-		channel.RemoveClient(targetClient)
+		c.server.RemoveFromChannel(channel, targetClient)
 	}
 
 	targetClient.Close()
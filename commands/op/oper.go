@@ -55,8 +55,12 @@ func (c *OperCommand) Execute(client *server.Client, params string) {
 						client.Vhost = operator.Vhost
 					}
 
+					if !client.IsLoggedIn() {
+						client.SetAccount(operator.Nick)
+					}
+
 					client.SendNumeric(utils.RPL_YOUREOPER, ":You are now an IRC operator")
-					client.SendMessage(utils.FormatUserMask(client.Nick, client.User, client.Host), "MODE", client.Nick, "+o")
+					client.SendMessage(client.Prefix(), "MODE", client.Nick, "+o")
 					logger.Info("IRCOP: User %s has logged in using %s", client.Nick, username)
 					return
 				} else {
@@ -57,39 +57,37 @@ func (c *OperBanCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
+	channelName = channel.Name
+
 	if !strings.ContainsAny(banMask, "*?") && !strings.Contains(banMask, "!") && !strings.Contains(banMask, "@") {
 		targetClient := c.server.GetClient(banMask)
 		if targetClient != nil {
-			banMask = targetClient.Nick + "!" + targetClient.User + "@" + targetClient.Host
+			banMask = utils.FormatMask(targetClient.Nick, targetClient.User, targetClient.GetHost())
 		} else {
 			banMask = banMask + "!*@*"
 		}
 	}
 
-	channel.SetBanned(banMask, true)
+	channel.AddBan(banMask, client.Prefix(), 0)
 
 	logger.Ban(client.Nick, banMask, channelName, reason)
 
-	modeMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " MODE " + channelName + " +b " + banMask
+	modeMsg := ":" + client.Prefix() + " MODE " + channelName + " +b " + banMask
 	channel.Broadcast(modeMsg)
 
 	for _, chanClient := range channel.GetClients() {
-		clientMask := chanClient.Nick + "!" + chanClient.User + "@" + chanClient.Host
-		if matchesBanMask(clientMask, banMask) && chanClient != client && !chanClient.IsOperator() {
+		if chanClient.MatchesMask(banMask) && chanClient != client && !chanClient.IsOperator() {
 			logger.Kick(client.Nick, chanClient.Nick, channelName, reason)
 
-			kickMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " KICK " + channelName + " " + chanClient.Nick + " :" + reason
+			kickMsg := ":" + client.Prefix() + " KICK " + channelName + " " + chanClient.Nick + " :" + reason
 			channel.Broadcast(kickMsg)
 
-			channel.RemoveClient(chanClient)
+			channel.RecordKick(chanClient)
+			c.server.RemoveFromChannel(channel, chanClient)
 		}
 	}
 }
 
-func matchesBanMask(clientMask, banMask string) bool {
-	return strings.Contains(clientMask, banMask) || banMask == clientMask
-}
-
 func (c *OperBanCommand) Help() string {
 	return "OPERBAN <channel> <mask> [<reason>] - IRC operator command to ban users matching the mask from the channel"
 }
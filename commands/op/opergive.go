@@ -48,6 +48,8 @@ func (c *OperGiveCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
+	channelName = channel.Name
+
 	targetClient := c.server.GetClient(targetNick)
 	if targetClient == nil {
 		client.SendNumeric(utils.ERR_NOSUCHNICK, targetNick+" :No such nick/channel")
@@ -63,7 +65,7 @@ func (c *OperGiveCommand) Execute(client *server.Client, params string) {
 
 	logger.IRCOp(client.Nick, "gave operator status to", targetNick, channelName)
 
-	modeMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " MODE " + channelName + " +o " + targetNick
+	modeMsg := ":" + client.Prefix() + " MODE " + channelName + " +o " + targetNick
 	channel.Broadcast(modeMsg)
 
 	targetClient.Send(":" + utils.SERVER_NAME + " NOTICE " + targetNick + " :You have been given channel operator status in " + channelName + " by IRC operator " + client.Nick)
@@ -48,6 +48,8 @@ func (c *OperRemoveCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
+	channelName = channel.Name
+
 	targetClient := c.server.GetClient(targetNick)
 	if targetClient == nil {
 		client.SendNumeric(utils.ERR_NOSUCHNICK, targetNick+" :No such nick/channel")
@@ -68,7 +70,7 @@ func (c *OperRemoveCommand) Execute(client *server.Client, params string) {
 
 	logger.IRCOp(client.Nick, "removed operator status from", targetNick, channelName)
 
-	modeMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " MODE " + channelName + " -o " + targetNick
+	modeMsg := ":" + client.Prefix() + " MODE " + channelName + " -o " + targetNick
 	channel.Broadcast(modeMsg)
 
 	targetClient.Send(":" + utils.SERVER_NAME + " NOTICE " + targetNick + " :Your channel operator status in " + channelName + " has been removed by IRC operator " + client.Nick)
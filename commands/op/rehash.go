@@ -0,0 +1,51 @@
+package op
+
+import (
+	"goircd/config"
+	"goircd/logger"
+	"goircd/server"
+	"goircd/utils"
+)
+
+type RehashCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("REHASH", func(s *server.Server) server.Command {
+		return &RehashCommand{server: s}
+	})
+}
+
+func (c *RehashCommand) Name() string {
+	return "REHASH"
+}
+
+func (c *RehashCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	if !client.IsOperator() {
+		client.SendNumeric(utils.ERR_NOPRIVILEGES, ":Permission Denied- You're not an IRC operator")
+		return
+	}
+
+	configPath := config.Path()
+	client.SendNumeric(utils.RPL_REHASHING, configPath+" :Rehashing")
+
+	if err := config.Reload(configPath); err != nil {
+		logger.Error("Rehash by %s failed: %v", client.Nick, err)
+		client.Send(":" + utils.SERVER_NAME + " NOTICE " + client.Nick + " :Rehash failed: " + err.Error())
+		return
+	}
+
+	c.server.ApplyChannelPresets()
+
+	logger.Info("Configuration reloaded by %s", client.Nick)
+}
+
+func (c *RehashCommand) Help() string {
+	return "REHASH - Reloads the configuration file and re-applies channel presets"
+}
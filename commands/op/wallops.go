@@ -0,0 +1,52 @@
+package op
+
+import (
+	"strings"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+type WallopsCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("WALLOPS", func(s *server.Server) server.Command {
+		return &WallopsCommand{server: s}
+	})
+}
+
+func (c *WallopsCommand) Name() string {
+	return "WALLOPS"
+}
+
+func (c *WallopsCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	if !client.IsOperator() {
+		client.SendNumeric(utils.ERR_NOPRIVILEGES, ":Permission Denied- You're not an IRC operator")
+		return
+	}
+
+	message := strings.TrimPrefix(params, ":")
+	if message == "" {
+		client.SendNumeric(utils.ERR_NEEDMOREPARAMS, "WALLOPS :Not enough parameters")
+		return
+	}
+
+	wallopsMsg := ":" + client.Prefix() + " WALLOPS :" + message
+
+	for _, recipient := range c.server.GetAllClients() {
+		if recipient.IsRegistered() && recipient.HasUserMode(utils.USER_MODE_WALLOPS) {
+			recipient.Send(wallopsMsg)
+		}
+	}
+}
+
+func (c *WallopsCommand) Help() string {
+	return "WALLOPS :<message> - Sends a message to every user with user mode +w"
+}
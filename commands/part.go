@@ -52,18 +52,24 @@ func (c *PartCommand) Execute(client *server.Client, params string) {
 			continue
 		}
 
+		channelName = channel.Name
+
 		if !channel.HasClient(client) {
 			client.SendNumeric(utils.ERR_NOTONCHANNEL, channelName+" :You're not on that channel")
 			continue
 		}
 
-		partMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.GetHost()) + " PART " + channelName
+		partMsg := ":" + client.Prefix() + " PART " + channelName
 		if partMessage != "" {
 			partMsg += " :" + partMessage
 		}
 		channel.Broadcast(partMsg)
 
-		channel.RemoveClient(client)
+		if !isFloodExempt(channel, client) {
+			channel.RecordJoinPartFlood(client)
+		}
+
+		c.server.RemoveFromChannel(channel, client)
 	}
 }
 
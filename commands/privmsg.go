@@ -1,11 +1,6 @@
 package commands
 
-import (
-	"strings"
-
-	"goircd/server"
-	"goircd/utils"
-)
+import "goircd/server"
 
 type PrivmsgCommand struct {
 	server *server.Server
@@ -22,65 +17,14 @@ func (c *PrivmsgCommand) Name() string {
 }
 
 func (c *PrivmsgCommand) Execute(client *server.Client, params string) {
-	if !client.IsRegistered() {
-		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
-		return
-	}
-
-	if params == "" {
-		client.SendNumeric(utils.ERR_NORECIPIENT, ":No recipient given (PRIVMSG)")
-		return
-	}
-
-	parts := strings.SplitN(params, " ", 2)
-	if len(parts) < 2 {
-		client.SendNumeric(utils.ERR_NOTEXTTOSEND, ":No text to send")
-		return
-	}
-
-	target := parts[0]
-	message := parts[1]
-
-	if strings.HasPrefix(message, ":") {
-		message = message[1:]
-	}
-
-	if message == "" {
-		client.SendNumeric(utils.ERR_NOTEXTTOSEND, ":No text to send")
-		return
-	}
-
-	formattedMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " PRIVMSG " + target + " :" + message
-
-	if strings.HasPrefix(target, "#") || strings.HasPrefix(target, "&") {
-		channel := c.server.GetChannel(target)
-		if channel == nil {
-			client.SendNumeric(utils.ERR_NOSUCHCHANNEL, target+" :No such channel")
-			return
-		}
-
-		if !channel.HasClient(client) && channel.HasMode(server.ModeNoExternalMessages) {
-			client.SendNumeric(utils.ERR_CANNOTSENDTOCHAN, target+" :Cannot send to channel")
-			return
-		}
-
-		if channel.HasMode(server.ModeModerated) && !channel.IsVoiced(client) && !channel.IsOperator(client) {
-			client.SendNumeric(utils.ERR_CANNOTSENDTOCHAN, target+" :Cannot send to channel (+m)")
-			return
-		}
-
-		channel.BroadcastFrom(client, formattedMsg)
-	} else {
-		targetClient := c.server.GetClient(target)
-		if targetClient == nil {
-			client.SendNumeric(utils.ERR_NOSUCHNICK, target+" :No such nick/channel")
-			return
-		}
+	c.ExecuteTagged(client, nil, params)
+}
 
-		targetClient.Send(formattedMsg)
-	}
+func (c *PrivmsgCommand) ExecuteTagged(client *server.Client, tags map[string]string, params string) {
+	sender := &messageSender{server: c.server, client: client, command: "PRIVMSG", tags: tags}
+	sender.execute(params)
 }
 
 func (c *PrivmsgCommand) Help() string {
-	return "PRIVMSG <target> :<message> - Sends a message to a user or channel"
+	return "PRIVMSG <target>{,<target>} :<message> - Sends a message to users or channels (prefix a channel with @ or + to reach only its ops or voiced users)"
 }
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"goircd/server"
-	"goircd/utils"
 )
 
 type QuitCommand struct {
@@ -33,14 +32,14 @@ func (c *QuitCommand) Execute(client *server.Client, params string) {
 		quitMessage = client.Nick
 	}
 
-	quitMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.GetHost()) + " QUIT"
+	quitMsg := ":" + client.Prefix() + " QUIT"
 	if quitMessage != "" {
 		quitMsg += " :" + quitMessage
 	}
 
 	for _, channel := range client.GetChannels() {
 		channel.BroadcastFrom(client, quitMsg)
-		channel.RemoveClient(client)
+		c.server.RemoveFromChannel(channel, client)
 	}
 
 	client.Close()
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"strings"
+
+	"goircd/config"
+	"goircd/server"
+	"goircd/utils"
+)
+
+type SilenceCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("SILENCE", func(s *server.Server) server.Command {
+		return &SilenceCommand{server: s}
+	})
+}
+
+func (c *SilenceCommand) Name() string {
+	return "SILENCE"
+}
+
+func (c *SilenceCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	fields := strings.Fields(params)
+	if len(fields) == 0 {
+		for _, mask := range client.GetSilenceList() {
+			client.SendNumeric(utils.RPL_SILELIST, client.Nick+" "+mask)
+		}
+		client.SendNumeric(utils.RPL_ENDOFSILELIST, ":End of Silence List")
+		return
+	}
+
+	limit := config.Get().Users.SilenceLimit
+
+	for _, entry := range strings.Split(fields[0], ",") {
+		adding := !strings.HasPrefix(entry, "-")
+		mask := normalizeSilenceMask(strings.TrimLeft(entry, "+-"))
+		if mask == "" {
+			continue
+		}
+
+		if adding {
+			if len(client.GetSilenceList()) >= limit {
+				client.SendNumeric(utils.ERR_SILELISTFULL, mask+" :Your silence list is full")
+				continue
+			}
+			if client.AddSilence(mask) {
+				client.Send(":" + client.Prefix() + " SILENCE +" + mask)
+			}
+		} else if client.RemoveSilence(mask) {
+			client.Send(":" + client.Prefix() + " SILENCE -" + mask)
+		}
+	}
+}
+
+// normalizeSilenceMask expands a bare nickname into nick!*@* and fills in
+// the missing parts of a partial mask.
+func normalizeSilenceMask(mask string) string {
+	if mask == "" {
+		return ""
+	}
+	if !strings.Contains(mask, "!") && !strings.Contains(mask, "@") {
+		return mask + "!*@*"
+	}
+	return utils.FormatMask(utils.ParseMask(mask))
+}
+
+func (c *SilenceCommand) Help() string {
+	return "SILENCE [{+|-}<mask>{,{+|-}<mask>}] - Lists, adds or removes masks whose private PRIVMSG, NOTICE and TAGMSG you do not want to receive"
+}
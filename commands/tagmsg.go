@@ -0,0 +1,30 @@
+package commands
+
+import "goircd/server"
+
+type TagmsgCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("TAGMSG", func(s *server.Server) server.Command {
+		return &TagmsgCommand{server: s}
+	})
+}
+
+func (c *TagmsgCommand) Name() string {
+	return "TAGMSG"
+}
+
+func (c *TagmsgCommand) Execute(client *server.Client, params string) {
+	c.ExecuteTagged(client, nil, params)
+}
+
+func (c *TagmsgCommand) ExecuteTagged(client *server.Client, tags map[string]string, params string) {
+	sender := &messageSender{server: c.server, client: client, command: "TAGMSG", tags: tags}
+	sender.execute(params)
+}
+
+func (c *TagmsgCommand) Help() string {
+	return "TAGMSG <target>{,<target>} - Sends only message tags, such as typing notifications, to users or channels (requires the message-tags capability to receive)"
+}
@@ -42,6 +42,8 @@ func (c *TopicCommand) Execute(client *server.Client, params string) {
 		return
 	}
 
+	channelName = channel.Name
+
 	if !channel.HasClient(client) {
 		client.SendNumeric(utils.ERR_NOTONCHANNEL, channelName+" :You're not on that channel")
 		return
@@ -74,7 +76,7 @@ func (c *TopicCommand) Execute(client *server.Client, params string) {
 
 	channel.SetTopic(newTopic, client.Nick)
 
-	topicMsg := ":" + utils.FormatUserMask(client.Nick, client.User, client.Host) + " TOPIC " + channelName + " :" + newTopic
+	topicMsg := ":" + client.Prefix() + " TOPIC " + channelName + " :" + newTopic
 	channel.Broadcast(topicMsg)
 }
 
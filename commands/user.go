@@ -44,8 +44,7 @@ func (c *UserCommand) Execute(client *server.Client, params string) {
 	client.SetUser(username, realname)
 
 	if client.Nick != "" && !client.IsRegistered() {
-		client.SetRegistered()
-		server.SendWelcomeMessages(client)
+		c.server.CompleteRegistration(client)
 	}
 }
 
@@ -39,23 +39,20 @@ func (c *UserhostCommand) Execute(client *server.Client, params string) {
 
 	var responses []string
 
-	for _, nick := range nicknames {
-		targetClient := c.server.GetClient(nick)
-		if targetClient != nil {
-			operatorMark := ""
-			if targetClient.IsOperator() {
-				operatorMark = "*"
-			}
-
-			awayStatus := "+"
-
-			if targetClient.IsAway() {
-				awayStatus = "-"
-			}
-
-			response := nick + operatorMark + "=" + awayStatus + targetClient.User + "@" + targetClient.Host
-			responses = append(responses, response)
+	for _, targetClient := range findClients(c.server, nicknames) {
+		operatorMark := ""
+		if targetClient.IsOperator() {
+			operatorMark = "*"
 		}
+
+		awayStatus := "+"
+
+		if targetClient.IsAway() {
+			awayStatus = "-"
+		}
+
+		response := targetClient.Nick + operatorMark + "=" + awayStatus + targetClient.User + "@" + targetClient.HostFor(client)
+		responses = append(responses, response)
 	}
 
 	if len(responses) > 0 {
@@ -65,6 +62,24 @@ func (c *UserhostCommand) Execute(client *server.Client, params string) {
 	}
 }
 
+// findClients looks up each of nicks, ignoring case, and returns the clients
+// that are online in the order they were asked for. A nick given twice is
+// only returned once. As with WHOIS, invisible (+i) users are found: +i only
+// hides users from WHO and NAMES listings, not from lookups by nickname.
+func findClients(s *server.Server, nicks []string) []*server.Client {
+	var clients []*server.Client
+	seen := make(map[*server.Client]bool)
+
+	for _, nick := range nicks {
+		if target := s.FindClient(nick); target != nil && !seen[target] {
+			seen[target] = true
+			clients = append(clients, target)
+		}
+	}
+
+	return clients
+}
+
 func (c *UserhostCommand) Help() string {
 	return "USERHOST <nickname> [<nickname> ...] - Shows host information for specified users"
 }
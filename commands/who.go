@@ -36,16 +36,22 @@ func (c *WhoCommand) Execute(client *server.Client, params string) {
 	if strings.HasPrefix(mask, "#") || strings.HasPrefix(mask, "&") {
 		channel := c.server.GetChannel(mask)
 		if channel != nil {
+			isMember := channel.HasClient(client)
 			for _, member := range channel.GetClients() {
-				sendWhoReply(client, member, mask, channel)
+				if isMember || member.IsVisibleTo(client) {
+					sendWhoReply(client, member, channel.Name, channel)
+				}
 			}
 		}
 	} else {
 		for _, channel := range c.server.GetAllChannels() {
 			for _, member := range channel.GetClients() {
+				if !member.IsVisibleTo(client) {
+					continue
+				}
 				if mask == "" || strings.Contains(member.Nick, mask) ||
 					strings.Contains(member.User, mask) ||
-					strings.Contains(member.Host, mask) {
+					strings.Contains(member.HostFor(client), mask) {
 					sendWhoReply(client, member, channel.Name, channel)
 					break
 				}
@@ -69,6 +75,10 @@ func sendWhoReply(client *server.Client, target *server.Client, channelName stri
 		status += "*"
 	}
 
+	if target.HasUserMode(utils.USER_MODE_BOT) {
+		status += "B"
+	}
+
 	if channel != nil {
 		if channel.IsOperator(target) {
 			status += "@"
@@ -79,7 +89,7 @@ func sendWhoReply(client *server.Client, target *server.Client, channelName stri
 
 	reply := channelName + " " +
 		target.User + " " +
-		target.GetHost() + " " +
+		target.HostFor(client) + " " +
 		utils.SERVER_NAME + " " +
 		target.Nick + " " +
 		status + " :0 " +
@@ -55,6 +55,18 @@ func (c *WhoisCommand) Execute(client *server.Client, params string) {
 		client.SendNumeric(utils.RPL_WHOISOPERATOR, targetNick+" :"+whois)
 	}
 
+	if targetClient.HasUserMode(utils.USER_MODE_BOT) {
+		client.SendNumeric(utils.RPL_WHOISBOT, targetNick+" :is a bot on "+utils.SERVER_NAME)
+	}
+
+	if (targetClient.HasHiddenHost() || targetClient.Host != targetClient.IP) && (client == targetClient || client.IsOperator()) {
+		client.SendNumeric(utils.RPL_WHOISACTUALLY, targetNick+" "+targetClient.IP+" :actually using host")
+	}
+
+	if account := targetClient.Account(); account != "" {
+		client.SendNumeric(utils.RPL_WHOISACCOUNT, targetNick+" "+account+" :is logged in as")
+	}
+
 	idleTime := int(targetClient.GetIdleTime().Seconds())
 	client.SendNumeric(utils.RPL_WHOISIDLE, targetNick+" "+strconv.Itoa(idleTime)+" :seconds idle")
 
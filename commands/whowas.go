@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+type WhowasCommand struct {
+	server *server.Server
+}
+
+func init() {
+	server.RegisterCommandInit("WHOWAS", func(s *server.Server) server.Command {
+		return &WhowasCommand{server: s}
+	})
+}
+
+func (c *WhowasCommand) Name() string {
+	return "WHOWAS"
+}
+
+func (c *WhowasCommand) Execute(client *server.Client, params string) {
+	if !client.IsRegistered() {
+		client.SendNumeric(utils.ERR_NOTREGISTERED, ":You have not registered")
+		return
+	}
+
+	parts := strings.Fields(params)
+	if len(parts) == 0 {
+		client.SendNumeric(utils.ERR_NONICKNAMEGIVEN, ":No nickname given")
+		return
+	}
+
+	count := 0
+	if len(parts) > 1 {
+		count, _ = strconv.Atoi(parts[1])
+	}
+
+	for _, nick := range strings.Split(parts[0], ",") {
+		if nick == "" {
+			continue
+		}
+
+		entries := c.server.GetWhowas(nick, count)
+		if len(entries) == 0 {
+			client.SendNumeric(utils.ERR_WASNOSUCHNICK, nick+" :There was no such nickname")
+		}
+
+		for _, entry := range entries {
+			host := entry.Host
+			if client.IsOperator() {
+				host = entry.RealHost
+			}
+
+			client.SendNumeric(utils.RPL_WHOWASUSER, entry.Nick+" "+entry.User+" "+host+" * :"+entry.RealName)
+			client.SendNumeric(utils.RPL_WHOISSERVER, entry.Nick+" "+entry.Server+" :"+entry.SignOff.UTC().Format(time.RFC1123))
+		}
+
+		client.SendNumeric(utils.RPL_ENDOFWHOWAS, nick+" :End of WHOWAS")
+	}
+}
+
+func (c *WhowasCommand) Help() string {
+	return "WHOWAS <nickname>{,<nickname>} [<count>] - Shows information about users who have left or changed nickname"
+}
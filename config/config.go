@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/goccy/go-yaml"
@@ -14,17 +15,21 @@ type Config struct {
 	Security SecurityConfig `yaml:"security"`
 	Channels ChannelsConfig `yaml:"channels"`
 	Users    UsersConfig    `yaml:"users"`
+	History  HistoryConfig  `yaml:"history"`
+	DNS      DNSConfig      `yaml:"dns"`
 	MOTD     string         `yaml:"-"`
 }
 
 type ServerConfig struct {
-	Name        string `yaml:"name"`
-	Host        string `yaml:"host"`
-	Port        int    `yaml:"port"`
-	Description string `yaml:"description"`
-	MOTD        string `yaml:"motd"`
-	MaxClients  int    `yaml:"max_clients"`
-	Timeout     int    `yaml:"timeout"`
+	Name         string `yaml:"name"`
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port"`
+	Description  string `yaml:"description"`
+	MOTD         string `yaml:"motd"`
+	MaxClients   int    `yaml:"max_clients"`
+	Timeout      int    `yaml:"timeout"`
+	Ident        bool   `yaml:"ident"`
+	IdentTimeout int    `yaml:"ident_timeout"`
 }
 
 type LoggingConfig struct {
@@ -42,6 +47,7 @@ type SecurityConfig struct {
 	MaxChannelName int        `yaml:"max_channel_name"`
 	MaskHosts      bool       `yaml:"mask_hosts"`
 	Secret         string     `yaml:"secret"`
+	OldSecrets     []string   `yaml:"old_secrets"`
 	BannedNicks    []string   `yaml:"banned_nicks"`
 	AllowedHosts   []string   `yaml:"allowed_hosts"`
 	Operators      []Operator `yaml:"operators"`
@@ -56,21 +62,73 @@ type Operator struct {
 }
 
 type ChannelsConfig struct {
-	DefaultModes       string   `yaml:"default_modes"`
-	MaxChannels        int      `yaml:"max_channels"`
-	MaxUsersPerChannel int      `yaml:"max_users_per_channel"`
-	DefaultChannels    []string `yaml:"default_channels"`
-	AutoJoin           bool     `yaml:"auto_join"`
+	DefaultModes       string          `yaml:"default_modes"`
+	MaxChannels        int             `yaml:"max_channels"`
+	MaxUsersPerChannel int             `yaml:"max_users_per_channel"`
+	DefaultChannels    []string        `yaml:"default_channels"`
+	AutoJoin           bool            `yaml:"auto_join"`
+	Presets            []ChannelPreset `yaml:"presets"`
+}
+
+// ChannelPreset describes a channel whose settings come from the
+// configuration. Permanent preset channels are created at startup, others
+// when first joined, and their settings are re-applied whenever the
+// configuration is reloaded. Leaving Permanent unset keeps whatever +P an
+// operator has set.
+type ChannelPreset struct {
+	Name      string   `yaml:"name"`
+	Topic     string   `yaml:"topic"`
+	EntryMsg  string   `yaml:"entry_message"`
+	Modes     string   `yaml:"modes"`
+	Key       string   `yaml:"key"`
+	Limit     int      `yaml:"limit"`
+	Bans      []string `yaml:"bans"`
+	AutoOp    []string `yaml:"auto_op"`
+	Permanent *bool    `yaml:"permanent"`
+}
+
+// Preset returns the preset for the named channel, or nil if there is none.
+func (c *ChannelsConfig) Preset(name string) *ChannelPreset {
+	for i := range c.Presets {
+		if strings.EqualFold(c.Presets[i].Name, name) {
+			return &c.Presets[i]
+		}
+	}
+	return nil
 }
 
 type UsersConfig struct {
 	MaxIdleTime      int `yaml:"max_idle_time"`
 	PingInterval     int `yaml:"ping_interval"`
 	MaxMessageLength int `yaml:"max_message_length"`
+	WhowasLength     int `yaml:"whowas_length"`
+	WhowasPerNick    int `yaml:"whowas_per_nick"`
+	MonitorLimit     int `yaml:"monitor_limit"`
+	SilenceLimit     int `yaml:"silence_limit"`
+}
+
+type HistoryConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	MaxMessages int    `yaml:"max_messages"`
+	MaxAge      int    `yaml:"max_age"`
+	QueryLimit  int    `yaml:"query_limit"`
+	Persist     bool   `yaml:"persist"`
+	Directory   string `yaml:"directory"`
+}
+
+// DNSConfig controls the reverse DNS lookups made for connecting clients.
+// Resolver is a host:port to query instead of the system resolver; the
+// hosts file is still consulted first.
+type DNSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Resolver string `yaml:"resolver"`
+	Timeout  int    `yaml:"timeout"`
+	CacheTTL int    `yaml:"cache_ttl"`
 }
 
 var (
 	instance *Config
+	path     string
 	once     sync.Once
 	mu       sync.RWMutex
 )
@@ -79,12 +137,21 @@ func Load(configPath string) error {
 	var err error
 
 	once.Do(func() {
+		path = configPath
 		instance, err = loadConfig(configPath)
 	})
 
 	return err
 }
 
+// Path returns the file the configuration was loaded from.
+func Path() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return path
+}
+
 func Get() *Config {
 	mu.RLock()
 	defer mu.RUnlock()
@@ -104,6 +171,7 @@ func Reload(configPath string) error {
 	}
 
 	instance = newConfig
+	path = configPath
 	return nil
 }
 
@@ -149,6 +217,9 @@ func setDefaults(config *Config) {
 	if config.Server.Timeout == 0 {
 		config.Server.Timeout = 300 // 5 minutes
 	}
+	if config.Server.IdentTimeout == 0 {
+		config.Server.IdentTimeout = 5
+	}
 
 	// Logging defaults
 	if config.Logging.Level == "" {
@@ -190,4 +261,35 @@ func setDefaults(config *Config) {
 	if config.Users.MaxMessageLength == 0 {
 		config.Users.MaxMessageLength = 512
 	}
+	if config.Users.WhowasLength <= 0 {
+		config.Users.WhowasLength = 1000
+	}
+	if config.Users.WhowasPerNick <= 0 {
+		config.Users.WhowasPerNick = 10
+	}
+	if config.Users.MonitorLimit == 0 {
+		config.Users.MonitorLimit = 100
+	}
+	if config.Users.SilenceLimit == 0 {
+		config.Users.SilenceLimit = 15
+	}
+
+	// History defaults
+	if config.History.MaxMessages <= 0 {
+		config.History.MaxMessages = 1000
+	}
+	if config.History.QueryLimit <= 0 {
+		config.History.QueryLimit = 100
+	}
+	if config.History.Directory == "" {
+		config.History.Directory = "history"
+	}
+
+	// DNS defaults
+	if config.DNS.Timeout == 0 {
+		config.DNS.Timeout = 5
+	}
+	if config.DNS.CacheTTL == 0 {
+		config.DNS.CacheTTL = 3600 // 1 hour
+	}
 }
@@ -0,0 +1,76 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Cloak returns the cloaked form of host, an IP address or a resolved
+// hostname, keyed by secret. Cloaks keep the structure of the host so that
+// a ban on a cloak can cover a whole network:
+//
+//	IPv4  203.0.113.7        -> 1A2B3C4D.5E6F7A8B.9C0D1E2F.IP  (/32, /24, /16)
+//	IPv6  2001:db8:1:2::5    -> 1A2B3C4D:5E6F7A8B:9C0D1E2F:IP  (/128, /64, /48)
+//	host  cpe-7.example.com  -> goircd-1A2B3C4D.example.com
+func Cloak(host, secret string) string {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return cloakIPv4(ip4, secret)
+		}
+		return cloakIPv6(ip, secret)
+	}
+	return cloakHostname(host, secret)
+}
+
+func cloakIPv4(ip net.IP, secret string) string {
+	return strings.Join([]string{
+		segment(secret, ip.String()),
+		segment(secret, maskedIP(ip, 24, 32)),
+		segment(secret, maskedIP(ip, 16, 32)),
+		"IP",
+	}, ".")
+}
+
+func cloakIPv6(ip net.IP, secret string) string {
+	return strings.Join([]string{
+		segment(secret, ip.String()),
+		segment(secret, maskedIP(ip, 64, 128)),
+		segment(secret, maskedIP(ip, 48, 128)),
+		"IP",
+	}, ":")
+}
+
+// cloakHostname replaces the leftmost label with a hash of the whole name,
+// keeping the domain. Names too short to have a separate domain keep only
+// their top-level label.
+func cloakHostname(host, secret string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	labels := strings.Split(host, ".")
+
+	var domain string
+	switch {
+	case len(labels) >= 3:
+		domain = strings.Join(labels[1:], ".")
+	case len(labels) == 2:
+		domain = labels[1]
+	}
+
+	cloak := "goircd-" + segment(secret, host)
+	if domain == "" {
+		return cloak
+	}
+	return cloak + "." + domain
+}
+
+func maskedIP(ip net.IP, ones, bits int) string {
+	return ip.Mask(net.CIDRMask(ones, bits)).String() + fmt.Sprintf("/%d", ones)
+}
+
+func segment(secret, value string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(value))
+	return fmt.Sprintf("%X", h.Sum(nil)[:4])
+}
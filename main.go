@@ -17,6 +17,7 @@ import (
 
 	_ "goircd/commands"
 	_ "goircd/commands/op"
+	_ "goircd/modes"
 )
 
 func main() {
@@ -0,0 +1,23 @@
+package modes
+
+import (
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	for _, letter := range []rune{
+		utils.CHAN_MODE_NO_CTCP,
+		utils.CHAN_MODE_MOD_UNREG,
+		utils.CHAN_MODE_REGISTERED,
+		utils.CHAN_MODE_STRIP_COLOUR,
+		utils.CHAN_MODE_NO_COLOUR,
+		utils.CHAN_MODE_INVITE_ONLY,
+		utils.CHAN_MODE_NO_EXTERNAL,
+		utils.CHAN_MODE_PRIVATE,
+		utils.CHAN_MODE_SECRET,
+		utils.CHAN_MODE_TOPIC_OP_ONLY,
+	} {
+		server.RegisterChannelMode(server.FlagMode(letter))
+	}
+}
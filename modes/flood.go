@@ -0,0 +1,44 @@
+package modes
+
+import (
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	server.RegisterChannelMode(&server.ChannelModeDef{
+		Letter: utils.CHAN_MODE_FLOOD,
+		Type:   server.ModeTypeParamOnSet,
+		Validate: func(change *server.ModeChange) error {
+			if !change.Adding {
+				return nil
+			}
+
+			settings, err := server.ParseFloodSettings(change.Param)
+			if err != nil {
+				return &server.ModeError{Numeric: utils.ERR_NEEDMOREPARAMS, Text: "MODE :Invalid flood settings: " + err.Error()}
+			}
+
+			change.Param = settings.String()
+			change.Value = settings
+			return nil
+		},
+		Apply: func(change *server.ModeChange) bool {
+			change.Channel.SetFloodSettings(change.Value.(*server.FloodSettings))
+			return true
+		},
+		Unapply: func(change *server.ModeChange) bool {
+			if change.Channel.GetFloodSettings() == nil {
+				return false
+			}
+			change.Channel.SetFloodSettings(nil)
+			return true
+		},
+		Value: func(ch *server.Channel) (string, bool) {
+			if flood := ch.GetFloodSettings(); flood != nil {
+				return flood.String(), true
+			}
+			return "", false
+		},
+	})
+}
@@ -0,0 +1,51 @@
+package modes
+
+import (
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	server.RegisterChannelMode(&server.ChannelModeDef{
+		Letter: utils.CHAN_MODE_FORWARD,
+		Type:   server.ModeTypeParamOnSet,
+		Validate: func(change *server.ModeChange) error {
+			if !change.Adding {
+				return nil
+			}
+
+			forwardChannel := change.Server.GetChannel(change.Param)
+			if forwardChannel == nil {
+				return &server.ModeError{Numeric: utils.ERR_NOSUCHCHANNEL, Text: change.Param + " :No such channel"}
+			}
+
+			change.Param = forwardChannel.Name
+
+			if forwardChannel == change.Channel {
+				return &server.ModeError{Numeric: utils.ERR_UNKNOWNMODE, Text: "f :Cannot forward a channel to itself"}
+			}
+
+			// Forwarding users into a channel requires ops there as well.
+			if change.Client != nil && !forwardChannel.IsOperator(change.Client) {
+				return &server.ModeError{Numeric: utils.ERR_CHANOPRIVSNEEDED, Text: forwardChannel.Name + " :You're not channel operator"}
+			}
+
+			change.Param = forwardChannel.Name
+			return nil
+		},
+		Apply: func(change *server.ModeChange) bool {
+			change.Channel.Forward = change.Param
+			return true
+		},
+		Unapply: func(change *server.ModeChange) bool {
+			if change.Channel.Forward == "" {
+				return false
+			}
+			change.Channel.Forward = ""
+			return true
+		},
+		Value: func(ch *server.Channel) (string, bool) {
+			return ch.Forward, ch.Forward != ""
+		},
+	})
+}
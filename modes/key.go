@@ -0,0 +1,33 @@
+package modes
+
+import (
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	server.RegisterChannelMode(&server.ChannelModeDef{
+		Letter: utils.CHAN_MODE_KEY,
+		Type:   server.ModeTypeSetting,
+		Validate: func(change *server.ModeChange) error {
+			if change.Adding && change.Channel.Key != "" {
+				return &server.ModeError{Numeric: utils.ERR_KEYSET, Text: change.Channel.Name + " :Channel key already set"}
+			}
+			return nil
+		},
+		Apply: func(change *server.ModeChange) bool {
+			change.Channel.Key = change.Param
+			return true
+		},
+		Unapply: func(change *server.ModeChange) bool {
+			if change.Channel.Key == "" {
+				return false
+			}
+			change.Channel.Key = ""
+			return true
+		},
+		Value: func(ch *server.Channel) (string, bool) {
+			return ch.Key, ch.Key != ""
+		},
+	})
+}
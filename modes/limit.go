@@ -0,0 +1,43 @@
+package modes
+
+import (
+	"strconv"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	server.RegisterChannelMode(&server.ChannelModeDef{
+		Letter: utils.CHAN_MODE_LIMIT,
+		Type:   server.ModeTypeParamOnSet,
+		Validate: func(change *server.ModeChange) error {
+			if !change.Adding {
+				return nil
+			}
+
+			limit, err := strconv.Atoi(change.Param)
+			if err != nil || limit < 0 {
+				return &server.ModeError{Numeric: utils.ERR_NEEDMOREPARAMS, Text: "MODE :Invalid limit"}
+			}
+
+			change.Param = strconv.Itoa(limit)
+			change.Value = limit
+			return nil
+		},
+		Apply: func(change *server.ModeChange) bool {
+			change.Channel.Limit = change.Value.(int)
+			return true
+		},
+		Unapply: func(change *server.ModeChange) bool {
+			if change.Channel.Limit == 0 {
+				return false
+			}
+			change.Channel.Limit = 0
+			return true
+		},
+		Value: func(ch *server.Channel) (string, bool) {
+			return strconv.Itoa(ch.Limit), ch.Limit > 0
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package modes
+
+import (
+	"time"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+type timedMask struct {
+	mask     string
+	duration time.Duration
+}
+
+func init() {
+	server.RegisterChannelMode(listMode(utils.CHAN_MODE_BAN, (*server.Channel).AddBan, (*server.Channel).RemoveBan))
+	server.RegisterChannelMode(listMode(utils.CHAN_MODE_EXCEPTION, (*server.Channel).AddException, (*server.Channel).RemoveException))
+	server.RegisterChannelMode(listMode(utils.CHAN_MODE_QUIET, (*server.Channel).AddQuiet, (*server.Channel).RemoveQuiet))
+}
+
+// listMode declares a mask list mode. Masks may be given as ~t:<duration>:<mask>
+// to have the entry expire on its own.
+func listMode(letter rune, add func(*server.Channel, string, string, time.Duration), remove func(*server.Channel, string) bool) *server.ChannelModeDef {
+	return &server.ChannelModeDef{
+		Letter: letter,
+		Type:   server.ModeTypeList,
+		Validate: func(change *server.ModeChange) error {
+			mask, duration, ok := utils.ParseTimedMask(change.Param)
+			if !ok {
+				return &server.ModeError{Numeric: utils.ERR_NEEDMOREPARAMS, Text: "MODE :Invalid timed mask, expected ~t:<duration>:<mask>"}
+			}
+
+			if change.Adding && change.Channel.ListSize() >= utils.MAX_BANS_PER_CHANNEL {
+				return &server.ModeError{Numeric: utils.ERR_BANLISTFULL, Text: change.Channel.Name + " " + string(letter) + " :Channel list is full"}
+			}
+
+			change.Param = mask
+			change.Value = timedMask{mask: mask, duration: duration}
+			return nil
+		},
+		Apply: func(change *server.ModeChange) bool {
+			entry := change.Value.(timedMask)
+			add(change.Channel, entry.mask, setterMask(change), entry.duration)
+			return true
+		},
+		Unapply: func(change *server.ModeChange) bool {
+			return remove(change.Channel, change.Value.(timedMask).mask)
+		},
+	}
+}
+
+// setterMask names whoever made a change, for list entries and the like.
+func setterMask(change *server.ModeChange) string {
+	if change.Client == nil {
+		return utils.SERVER_NAME
+	}
+	return change.Client.Prefix()
+}
@@ -0,0 +1,58 @@
+package modes
+
+import (
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	server.RegisterChannelMode(&server.ChannelModeDef{
+		Letter:   utils.CHAN_MODE_OP,
+		Type:     server.ModeTypeMembership,
+		Validate: validateMember,
+		Apply: func(change *server.ModeChange) bool {
+			return setMemberStatus(change, (*server.Channel).IsOperator, (*server.Channel).SetOperator, true)
+		},
+		Unapply: func(change *server.ModeChange) bool {
+			return setMemberStatus(change, (*server.Channel).IsOperator, (*server.Channel).SetOperator, false)
+		},
+	})
+
+	server.RegisterChannelMode(&server.ChannelModeDef{
+		Letter:   utils.CHAN_MODE_VOICE,
+		Type:     server.ModeTypeMembership,
+		Validate: validateMember,
+		Apply: func(change *server.ModeChange) bool {
+			return setMemberStatus(change, (*server.Channel).IsVoiced, (*server.Channel).SetVoiced, true)
+		},
+		Unapply: func(change *server.ModeChange) bool {
+			return setMemberStatus(change, (*server.Channel).IsVoiced, (*server.Channel).SetVoiced, false)
+		},
+	})
+}
+
+// validateMember resolves the nickname parameter to a channel member.
+func validateMember(change *server.ModeChange) error {
+	target := change.Server.FindClient(change.Param)
+	if target == nil {
+		return &server.ModeError{Numeric: utils.ERR_NOSUCHNICK, Text: change.Param + " :No such nick/channel"}
+	}
+
+	if !change.Channel.HasClient(target) {
+		return &server.ModeError{Numeric: utils.ERR_USERNOTINCHANNEL, Text: change.Param + " " + change.Channel.Name + " :They aren't on that channel"}
+	}
+
+	change.Param = target.Nick
+	change.Value = target
+	return nil
+}
+
+func setMemberStatus(change *server.ModeChange, has func(*server.Channel, *server.Client) bool, set func(*server.Channel, *server.Client, bool), enabled bool) bool {
+	target := change.Value.(*server.Client)
+	if has(change.Channel, target) == enabled {
+		return false
+	}
+
+	set(change.Channel, target, enabled)
+	return true
+}
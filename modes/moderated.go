@@ -0,0 +1,24 @@
+package modes
+
+import (
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	def := server.FlagMode(utils.CHAN_MODE_MODERATED)
+
+	// An operator changing +m takes over from flood protection, which then
+	// no longer lifts it automatically.
+	apply, unapply := def.Apply, def.Unapply
+	def.Apply = func(change *server.ModeChange) bool {
+		change.Channel.SetFloodModerated(false)
+		return apply(change)
+	}
+	def.Unapply = func(change *server.ModeChange) bool {
+		change.Channel.SetFloodModerated(false)
+		return unapply(change)
+	}
+
+	server.RegisterChannelMode(def)
+}
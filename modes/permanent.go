@@ -0,0 +1,13 @@
+package modes
+
+import (
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	def := server.FlagMode(utils.CHAN_MODE_PERMANENT)
+	def.OperOnly = true
+
+	server.RegisterChannelMode(def)
+}
@@ -0,0 +1,44 @@
+package modes
+
+import (
+	"strconv"
+	"time"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	server.RegisterChannelMode(&server.ChannelModeDef{
+		Letter: utils.CHAN_MODE_KICK_DELAY,
+		Type:   server.ModeTypeParamOnSet,
+		Validate: func(change *server.ModeChange) error {
+			if !change.Adding {
+				return nil
+			}
+
+			delay, err := strconv.Atoi(change.Param)
+			if err != nil || delay <= 0 {
+				return &server.ModeError{Numeric: utils.ERR_NEEDMOREPARAMS, Text: "MODE :Invalid rejoin delay"}
+			}
+
+			change.Param = strconv.Itoa(delay)
+			change.Value = time.Duration(delay) * time.Second
+			return nil
+		},
+		Apply: func(change *server.ModeChange) bool {
+			change.Channel.KickDelay = change.Value.(time.Duration)
+			return true
+		},
+		Unapply: func(change *server.ModeChange) bool {
+			if change.Channel.KickDelay == 0 {
+				return false
+			}
+			change.Channel.KickDelay = 0
+			return true
+		},
+		Value: func(ch *server.Channel) (string, bool) {
+			return strconv.Itoa(int(ch.KickDelay.Seconds())), ch.KickDelay > 0
+		},
+	})
+}
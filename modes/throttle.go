@@ -0,0 +1,68 @@
+package modes
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"goircd/server"
+	"goircd/utils"
+)
+
+func init() {
+	server.RegisterChannelMode(&server.ChannelModeDef{
+		Letter: utils.CHAN_MODE_JOIN_THROTTLE,
+		Type:   server.ModeTypeParamOnSet,
+		Validate: func(change *server.ModeChange) error {
+			if !change.Adding {
+				return nil
+			}
+
+			joins, seconds, ok := parseJoinThrottle(change.Param)
+			if !ok {
+				return &server.ModeError{Numeric: utils.ERR_NEEDMOREPARAMS, Text: "MODE :Invalid join throttle, expected <joins>:<seconds>"}
+			}
+
+			change.Param = strconv.Itoa(joins) + ":" + strconv.Itoa(seconds)
+			change.Value = [2]int{joins, seconds}
+			return nil
+		},
+		Apply: func(change *server.ModeChange) bool {
+			throttle := change.Value.([2]int)
+			change.Channel.JoinLimit = throttle[0]
+			change.Channel.JoinWindow = time.Duration(throttle[1]) * time.Second
+			return true
+		},
+		Unapply: func(change *server.ModeChange) bool {
+			if change.Channel.JoinLimit == 0 {
+				return false
+			}
+			change.Channel.JoinLimit = 0
+			change.Channel.JoinWindow = 0
+			return true
+		},
+		Value: func(ch *server.Channel) (string, bool) {
+			return strconv.Itoa(ch.JoinLimit) + ":" + strconv.Itoa(int(ch.JoinWindow.Seconds())), ch.JoinLimit > 0
+		},
+	})
+}
+
+// parseJoinThrottle parses a +j parameter of the form <joins>:<seconds>.
+func parseJoinThrottle(value string) (int, int, bool) {
+	joinsStr, secondsStr, found := strings.Cut(value, ":")
+	if !found {
+		return 0, 0, false
+	}
+
+	joins, err := strconv.Atoi(joinsStr)
+	if err != nil || joins <= 0 {
+		return 0, 0, false
+	}
+
+	seconds, err := strconv.Atoi(secondsStr)
+	if err != nil || seconds <= 0 {
+		return 0, 0, false
+	}
+
+	return joins, seconds, true
+}
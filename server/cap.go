@@ -0,0 +1,137 @@
+package server
+
+import (
+	"strings"
+
+	"goircd/config"
+	"goircd/utils"
+)
+
+// Capabilities that clients can request with CAP REQ.
+const (
+	CapBatch       = "batch"
+	CapChatHistory = "draft/chathistory"
+	CapMessageTags = "message-tags"
+	CapServerTime  = "server-time"
+)
+
+// SupportedCaps returns the capabilities offered in CAP LS. The chathistory
+// capability is only offered while history is enabled.
+func SupportedCaps() []string {
+	caps := []string{CapBatch, CapMessageTags, CapServerTime}
+	if config.Get().History.Enabled {
+		caps = append(caps, CapChatHistory)
+	}
+	return caps
+}
+
+// IsSupportedCap reports whether name is offered in CAP LS.
+func IsSupportedCap(name string) bool {
+	for _, cap := range SupportedCaps() {
+		if cap == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) HasCap(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.caps[name]
+}
+
+func (c *Client) SetCap(name string, enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if enabled {
+		c.caps[name] = true
+	} else {
+		delete(c.caps, name)
+	}
+}
+
+// EnabledCaps returns the capabilities the client has enabled.
+func (c *Client) EnabledCaps() []string {
+	var caps []string
+	for _, cap := range SupportedCaps() {
+		if c.HasCap(cap) {
+			caps = append(caps, cap)
+		}
+	}
+	return caps
+}
+
+// BeginCapNegotiation holds registration until the client sends CAP END.
+func (c *Client) BeginCapNegotiation() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capNegotiating = true
+}
+
+// EndCapNegotiation releases registration. It returns false if the client
+// was not negotiating.
+func (c *Client) EndCapNegotiation() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	negotiating := c.capNegotiating
+	c.capNegotiating = false
+	return negotiating
+}
+
+func (c *Client) isNegotiatingCaps() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.capNegotiating
+}
+
+// SendTagged sends line with the message tags the client has asked for:
+// time needs server-time, batch needs batch, and all other tags need
+// message-tags. Clients without any of them get the plain line.
+func (c *Client) SendTagged(tags map[string]string, line string) {
+	allowed := make(map[string]string)
+	for key, value := range tags {
+		var cap string
+		switch key {
+		case "time":
+			cap = CapServerTime
+		case "batch":
+			cap = CapBatch
+		default:
+			cap = CapMessageTags
+		}
+		if c.HasCap(cap) {
+			allowed[key] = value
+		}
+	}
+
+	c.Send(utils.FormatTags(allowed) + line)
+}
+
+// splitTags separates the message tags a client may send before a command
+// from the rest of the line, keeping only client-only tags. Other tags are
+// set by the server and are dropped.
+func splitTags(line string) (map[string]string, string) {
+	if !strings.HasPrefix(line, "@") {
+		return nil, line
+	}
+
+	raw, rest, found := strings.Cut(line[1:], " ")
+	if !found {
+		return nil, ""
+	}
+
+	tags := make(map[string]string)
+	for key, value := range utils.ParseTags(raw) {
+		if strings.HasPrefix(key, "+") {
+			tags[key] = value
+		}
+	}
+
+	return tags, strings.TrimLeft(rest, " ")
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"sort"
+	"strings"
+)
+
+// ChannelModeType classifies a channel mode the way CHANMODES does in
+// RPL_ISUPPORT, plus membership modes such as +o which are advertised in
+// PREFIX instead.
+type ChannelModeType int
+
+const (
+	// ModeTypeList modes keep a list of masks and always take a parameter (A).
+	ModeTypeList ChannelModeType = iota
+	// ModeTypeSetting modes always take a parameter (B).
+	ModeTypeSetting
+	// ModeTypeParamOnSet modes take a parameter only when set (C).
+	ModeTypeParamOnSet
+	// ModeTypeFlag modes never take a parameter (D).
+	ModeTypeFlag
+	// ModeTypeMembership modes grant status to a member named by the parameter.
+	ModeTypeMembership
+)
+
+// ModeChange describes a single mode letter being set or unset on a channel.
+// Client is nil when the change is made by the server itself.
+type ModeChange struct {
+	Server  *Server
+	Channel *Channel
+	Client  *Client
+	Adding  bool
+	// Param is the mode's parameter. Validate may rewrite it into the form
+	// that is announced to the channel.
+	Param string
+	// Value carries whatever Validate parsed out of Param for Apply to use.
+	Value any
+}
+
+// ModeError rejects a mode change with the given numeric reply.
+type ModeError struct {
+	Numeric int
+	Text    string
+}
+
+func (e *ModeError) Error() string {
+	return e.Text
+}
+
+// ChannelModeDef declares a channel mode: its letter, how it takes a
+// parameter, who may change it and how it is applied.
+type ChannelModeDef struct {
+	Letter rune
+	Type   ChannelModeType
+	// OperOnly restricts changes to IRC operators.
+	OperOnly bool
+	// Validate checks a change before it is applied. It may be nil.
+	Validate func(change *ModeChange) error
+	// Apply and Unapply perform the change, reporting whether anything
+	// changed and so whether it should be announced.
+	Apply   func(change *ModeChange) bool
+	Unapply func(change *ModeChange) bool
+	// Value reports whether the mode is set on ch and its parameter, for
+	// RPL_CHANNELMODEIS. List and membership modes leave it nil.
+	Value func(ch *Channel) (string, bool)
+}
+
+// TakesParam reports whether the mode consumes a parameter when set or unset.
+func (def *ChannelModeDef) TakesParam(adding bool) bool {
+	switch def.Type {
+	case ModeTypeList, ModeTypeSetting, ModeTypeMembership:
+		return true
+	case ModeTypeParamOnSet:
+		return adding
+	}
+	return false
+}
+
+// AnnouncesParam reports whether the parameter is included when the change
+// is broadcast. Keys are not repeated back when a key is removed.
+func (def *ChannelModeDef) AnnouncesParam(adding bool) bool {
+	if adding {
+		return def.Type != ModeTypeFlag
+	}
+	return def.Type == ModeTypeList || def.Type == ModeTypeMembership
+}
+
+var ChannelModeRegistry = make(map[rune]*ChannelModeDef)
+
+func RegisterChannelMode(def *ChannelModeDef) {
+	ChannelModeRegistry[def.Letter] = def
+}
+
+func GetChannelMode(letter rune) *ChannelModeDef {
+	return ChannelModeRegistry[letter]
+}
+
+// ChannelModeDefs returns the registered modes ordered by letter.
+func ChannelModeDefs() []*ChannelModeDef {
+	defs := make([]*ChannelModeDef, 0, len(ChannelModeRegistry))
+	for _, def := range ChannelModeRegistry {
+		defs = append(defs, def)
+	}
+
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Letter < defs[j].Letter
+	})
+
+	return defs
+}
+
+// ChanModesToken builds the CHANMODES value advertised in RPL_ISUPPORT.
+func ChanModesToken() string {
+	groups := make([]strings.Builder, ModeTypeFlag+1)
+	for _, def := range ChannelModeDefs() {
+		if def.Type <= ModeTypeFlag {
+			groups[def.Type].WriteRune(def.Letter)
+		}
+	}
+
+	parts := make([]string, len(groups))
+	for i := range groups {
+		parts[i] = groups[i].String()
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// ChannelModeLetters returns every channel mode letter and those that take a
+// parameter, as listed in RPL_MYINFO.
+func ChannelModeLetters() (string, string) {
+	var all, withParam strings.Builder
+	for _, def := range ChannelModeDefs() {
+		all.WriteRune(def.Letter)
+		if def.TakesParam(true) {
+			withParam.WriteRune(def.Letter)
+		}
+	}
+	return all.String(), withParam.String()
+}
+
+// FlagMode declares a parameterless mode stored in the channel's mode set.
+func FlagMode(letter rune) *ChannelModeDef {
+	mode := ChannelMode(letter)
+
+	return &ChannelModeDef{
+		Letter: letter,
+		Type:   ModeTypeFlag,
+		Apply: func(change *ModeChange) bool {
+			return change.Channel.setModeChanged(mode, true)
+		},
+		Unapply: func(change *ModeChange) bool {
+			return change.Channel.setModeChanged(mode, false)
+		},
+		Value: func(ch *Channel) (string, bool) {
+			return "", ch.HasMode(mode)
+		},
+	}
+}
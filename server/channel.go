@@ -1,26 +1,29 @@
 package server
 
 import (
+	"goircd/config"
 	"goircd/utils"
 	"strings"
 	"sync"
 	"time"
 )
 
-type ChannelMode int
+// ChannelMode identifies a flag mode by its letter.
+type ChannelMode rune
 
 const (
-	ModePrivate ChannelMode = iota
-	ModeSecret
-	ModeInviteOnly
-	ModeTopicSettableByOpsOnly
-	ModeNoExternalMessages
-	ModeModerated
-	ModeLimit
-	ModeKey
-	ModeOp
-	ModeVoice
-	ModeBan
+	ModePrivate                ChannelMode = utils.CHAN_MODE_PRIVATE
+	ModeSecret                 ChannelMode = utils.CHAN_MODE_SECRET
+	ModeInviteOnly             ChannelMode = utils.CHAN_MODE_INVITE_ONLY
+	ModeTopicSettableByOpsOnly ChannelMode = utils.CHAN_MODE_TOPIC_OP_ONLY
+	ModeNoExternalMessages     ChannelMode = utils.CHAN_MODE_NO_EXTERNAL
+	ModeModerated              ChannelMode = utils.CHAN_MODE_MODERATED
+	ModePermanent              ChannelMode = utils.CHAN_MODE_PERMANENT
+	ModeNoColour               ChannelMode = utils.CHAN_MODE_NO_COLOUR
+	ModeStripColour            ChannelMode = utils.CHAN_MODE_STRIP_COLOUR
+	ModeNoCTCP                 ChannelMode = utils.CHAN_MODE_NO_CTCP
+	ModeRegisteredOnly         ChannelMode = utils.CHAN_MODE_REGISTERED
+	ModeModerateUnregistered   ChannelMode = utils.CHAN_MODE_MOD_UNREG
 )
 
 type Channel struct {
@@ -30,14 +33,29 @@ type Channel struct {
 	TopicSetAt time.Time
 	Key        string
 	Limit      int
+	Forward    string
+	JoinLimit  int
+	JoinWindow time.Duration
+	KickDelay  time.Duration
+	Flood      *FloodSettings
 	Modes      map[ChannelMode]bool
 	clients    map[*Client]bool
 	operators  map[*Client]bool
 	voiced     map[*Client]bool
-	banned     map[string]bool
+	banned     maskList
+	excepted   maskList
+	quieted    maskList
 	inviteList map[string]bool
+	flood      *floodState
+	joinTimes  []time.Time
+	kickTimes  map[string]time.Time
+	autoOp     []string
+	entryMsg   string
+	preset     bool
+	lastPreset *config.ChannelPreset
 	mu         sync.RWMutex
 	createdAt  time.Time
+	destroyed  bool
 }
 
 func NewChannel(name string) *Channel {
@@ -47,46 +65,64 @@ func NewChannel(name string) *Channel {
 		clients:    make(map[*Client]bool),
 		operators:  make(map[*Client]bool),
 		voiced:     make(map[*Client]bool),
-		banned:     make(map[string]bool),
+		banned:     make(maskList),
+		excepted:   make(maskList),
+		quieted:    make(maskList),
 		inviteList: make(map[string]bool),
+		flood:      newFloodState(),
+		kickTimes:  make(map[string]time.Time),
 		createdAt:  time.Now(),
 	}
 }
 
-func (ch *Channel) ApplyDefaultModes(modeString string) {
-	if modeString == "" {
-		return
-	}
-
+// ApplyDefaultModes sets the flag modes in modeString, such as "+nt", and
+// returns those that changed. Modes that take a parameter are ignored.
+func (ch *Channel) ApplyDefaultModes(modeString string) []utils.Mode {
+	var changes []utils.Mode
 	adding := true
 	for _, char := range modeString {
 		switch char {
 		case '+':
 			adding = true
+			continue
 		case '-':
 			adding = false
-		case 'n':
-			ch.SetMode(ModeNoExternalMessages, adding)
-		case 't':
-			ch.SetMode(ModeTopicSettableByOpsOnly, adding)
-		case 's':
-			ch.SetMode(ModeSecret, adding)
-		case 'p':
-			ch.SetMode(ModePrivate, adding)
-		case 'i':
-			ch.SetMode(ModeInviteOnly, adding)
-		case 'm':
-			ch.SetMode(ModeModerated, adding)
+			continue
+		}
+
+		def := GetChannelMode(char)
+		if def == nil || def.Type != ModeTypeFlag {
+			continue
+		}
+
+		change := &ModeChange{Channel: ch, Adding: adding}
+		var changed bool
+		if adding {
+			changed = def.Apply(change)
+		} else {
+			changed = def.Unapply(change)
+		}
+		if changed {
+			changes = append(changes, utils.Mode{Adding: adding, Letter: char})
 		}
 	}
+
+	return changes
 }
 
-func (ch *Channel) AddClient(client *Client) {
+// AddClient adds client to the channel. It returns false if the channel has
+// already been destroyed, in which case the caller should look it up again.
+func (ch *Channel) AddClient(client *Client) bool {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
 
+	if ch.destroyed {
+		return false
+	}
+
 	ch.clients[client] = true
 	client.JoinChannel(ch)
+	return true
 }
 
 func (ch *Channel) RemoveClient(client *Client) {
@@ -96,10 +132,39 @@ func (ch *Channel) RemoveClient(client *Client) {
 	delete(ch.clients, client)
 	delete(ch.operators, client)
 	delete(ch.voiced, client)
+	ch.flood.forget(client)
 
 	client.LeaveChannel(ch.Name)
 }
 
+// destroyIfEmpty marks the channel as destroyed and releases its lists if it
+// has no members left and is not permanent.
+func (ch *Channel) destroyIfEmpty() bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.destroyed || len(ch.clients) > 0 || ch.Modes[ModePermanent] {
+		return false
+	}
+
+	ch.destroyed = true
+	ch.operators = make(map[*Client]bool)
+	ch.voiced = make(map[*Client]bool)
+	ch.banned = make(maskList)
+	ch.excepted = make(maskList)
+	ch.quieted = make(maskList)
+	ch.inviteList = make(map[string]bool)
+	ch.flood = newFloodState()
+	ch.joinTimes = nil
+	ch.kickTimes = make(map[string]time.Time)
+
+	return true
+}
+
+func (ch *Channel) CreatedAt() time.Time {
+	return ch.createdAt
+}
+
 func (ch *Channel) GetClients() []*Client {
 	ch.mu.RLock()
 	defer ch.mu.RUnlock()
@@ -129,6 +194,22 @@ func (ch *Channel) SetTopic(topic, setBy string) {
 	ch.TopicSetAt = time.Now()
 }
 
+// SetEntryMessage sets the notice shown to users as they join. An empty
+// message disables it.
+func (ch *Channel) SetEntryMessage(message string) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.entryMsg = message
+}
+
+func (ch *Channel) GetEntryMessage() string {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.entryMsg
+}
+
 func (ch *Channel) GetTopic() (string, string, time.Time) {
 	ch.mu.RLock()
 	defer ch.mu.RUnlock()
@@ -143,6 +224,18 @@ func (ch *Channel) SetMode(mode ChannelMode, enabled bool) {
 	ch.Modes[mode] = enabled
 }
 
+// setModeChanged sets a flag mode and reports whether it was changed.
+func (ch *Channel) setModeChanged(mode ChannelMode, enabled bool) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.Modes[mode] == enabled {
+		return false
+	}
+	ch.Modes[mode] = enabled
+	return true
+}
+
 func (ch *Channel) HasMode(mode ChannelMode) bool {
 	ch.mu.RLock()
 	defer ch.mu.RUnlock()
@@ -186,61 +279,85 @@ func (ch *Channel) IsVoiced(client *Client) bool {
 	return ch.voiced[client]
 }
 
-func (ch *Channel) SetBanned(mask string, isBanned bool) {
+func (ch *Channel) SetInvited(nick string, isInvited bool) {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
 
-	if isBanned {
-		ch.banned[mask] = true
+	if isInvited {
+		ch.inviteList[strings.ToLower(nick)] = true
 	} else {
-		delete(ch.banned, mask)
+		delete(ch.inviteList, strings.ToLower(nick))
 	}
 }
 
-func (ch *Channel) IsBanned(client *Client) bool {
+func (ch *Channel) IsInvited(nick string) bool {
 	ch.mu.RLock()
 	defer ch.mu.RUnlock()
 
-	clientMask := utils.FormatMask(client.Nick, client.User, client.Host)
+	return ch.inviteList[strings.ToLower(nick)]
+}
 
-	for mask := range ch.banned {
-		if utils.MatchesBanMask(clientMask, mask) {
-			return true
-		}
-	}
+// IsJoinThrottled reports whether the +j limit of joins within the sliding
+// window has been reached.
+func (ch *Channel) IsJoinThrottled() bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
 
-	return false
+	if ch.JoinLimit <= 0 || ch.JoinWindow <= 0 {
+		return false
+	}
 
+	ch.joinTimes = pruneTimes(ch.joinTimes, ch.JoinWindow)
+	return len(ch.joinTimes) >= ch.JoinLimit
 }
 
-func (ch *Channel) GetBanList() []string {
-	ch.mu.RLock()
-	defer ch.mu.RUnlock()
+func (ch *Channel) RecordJoin() {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
 
-	banList := make([]string, 0, len(ch.banned))
-	for mask := range ch.banned {
-		banList = append(banList, mask)
+	if ch.JoinLimit <= 0 || ch.JoinWindow <= 0 {
+		return
 	}
 
-	return banList
+	ch.joinTimes = append(pruneTimes(ch.joinTimes, ch.JoinWindow), time.Now())
 }
 
-func (ch *Channel) SetInvited(nick string, isInvited bool) {
+// RecordKick remembers when client was kicked so that +J can delay their
+// rejoin. Kicks are tracked by user@host so a nick change does not evade it.
+func (ch *Channel) RecordKick(client *Client) {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
 
-	if isInvited {
-		ch.inviteList[strings.ToLower(nick)] = true
-	} else {
-		delete(ch.inviteList, strings.ToLower(nick))
+	if ch.KickDelay <= 0 {
+		return
 	}
+
+	cutoff := time.Now().Add(-ch.KickDelay)
+	for key, kickedAt := range ch.kickTimes {
+		if kickedAt.Before(cutoff) {
+			delete(ch.kickTimes, key)
+		}
+	}
+
+	ch.kickTimes[kickKey(client)] = time.Now()
 }
 
-func (ch *Channel) IsInvited(nick string) bool {
+// RejoinDelay returns how long client must still wait before rejoining
+// after a kick, or zero if they may rejoin now.
+func (ch *Channel) RejoinDelay(client *Client) time.Duration {
 	ch.mu.RLock()
 	defer ch.mu.RUnlock()
 
-	return ch.inviteList[strings.ToLower(nick)]
+	kickedAt, exists := ch.kickTimes[kickKey(client)]
+	if !exists || ch.KickDelay <= 0 {
+		return 0
+	}
+
+	return max(0, time.Until(kickedAt.Add(ch.KickDelay)))
+}
+
+func kickKey(client *Client) string {
+	return strings.ToLower(client.User + "@" + client.Host)
 }
 
 func (ch *Channel) Broadcast(message string) {
@@ -251,6 +368,27 @@ func (ch *Channel) Broadcast(message string) {
 	}
 }
 
+// MessageRecipients returns the members other than sender who should get a
+// channel message, skipping those who are deaf (+D). A status of '@' limits
+// them to operators and '+' to voiced users and operators; 0 means everyone.
+func (ch *Channel) MessageRecipients(sender *Client, status byte) []*Client {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	var recipients []*Client
+	for client := range ch.clients {
+		if client == sender || client.HasUserMode(utils.USER_MODE_DEAF) {
+			continue
+		}
+		if status != 0 && !ch.operators[client] && !(status == '+' && ch.voiced[client]) {
+			continue
+		}
+		recipients = append(recipients, client)
+	}
+
+	return recipients
+}
+
 func (ch *Channel) BroadcastFrom(sender *Client, message string) {
 	clients := ch.GetClients()
 
@@ -3,7 +3,7 @@ package server
 import (
 	"fmt"
 	"goircd/config"
-	"goircd/hash"
+	"goircd/utils"
 	"net"
 	"strings"
 	"sync"
@@ -11,44 +11,59 @@ import (
 )
 
 type Client struct {
-	conn        net.Conn
-	server      *Server
-	Nick        string
-	User        string
-	RealName    string
-	Host        string
-	Vhost       string
-	MHost       string
-	channels    map[string]*Channel
-	registered  bool
-	isOperator  bool
-	isAway      bool
-	Whois       string
-	AwayMessage string
-	lastPing    time.Time
-	mu          sync.RWMutex
-	sendMu      sync.Mutex
+	conn               net.Conn
+	server             *Server
+	Nick               string
+	User               string
+	RealName           string
+	Host               string
+	IP                 string
+	Vhost              string
+	MHost              string
+	altCloaks          []string
+	channels           map[string]*Channel
+	registered         bool
+	isOperator         bool
+	isAway             bool
+	account            string
+	userModes          map[rune]bool
+	listing            bool
+	accepted           map[string]string
+	monitoring         map[string]string
+	silenced           map[string]string
+	lastCallerIDNotice time.Time
+	Whois              string
+	AwayMessage        string
+	lastPing           time.Time
+	lookups            sync.WaitGroup
+	caps               map[string]bool
+	capNegotiating     bool
+	identChecked       bool
+	identUser          string
+	resolvedHost       string
+	mu                 sync.RWMutex
+	sendMu             sync.Mutex
 }
 
 func NewClient(conn net.Conn, server *Server) *Client {
 	host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
 
-	cfg := config.Get()
-
-	mhost := ""
-
-	if cfg.Security.MaskHosts {
-		mhost = hash.MaskIP(host)
+	client := &Client{
+		conn:       conn,
+		server:     server,
+		Host:       host,
+		IP:         host,
+		channels:   make(map[string]*Channel),
+		userModes:  make(map[rune]bool),
+		accepted:   make(map[string]string),
+		monitoring: make(map[string]string),
+		silenced:   make(map[string]string),
+		caps:       make(map[string]bool),
+		lastPing:   time.Now(),
 	}
+	client.cloakHost()
 
-	return &Client{
-		conn:     conn,
-		server:   server,
-		Host:     host,
-		MHost:    mhost,
-		channels: make(map[string]*Channel),
-		lastPing: time.Now(),
-	}
+	return client
 }
 
 func (c *Client) Send(message string) {
@@ -77,6 +92,23 @@ func (c *Client) SendMessage(source, command, target, message string) {
 	c.Send(fmt.Sprintf(":%s %s %s :%s", source, command, target, message))
 }
 
+// SendAuthNotice sends a server notice about the progress of the client's
+// connection, before or during registration. It is sent from the lookup
+// goroutines while the nick may still be changing, so it is always
+// addressed to *.
+func (c *Client) SendAuthNotice(text string) {
+	c.Send(":" + utils.SERVER_NAME + " NOTICE * :" + text)
+}
+
+// SendFail sends an IRCv3 standard FAIL reply.
+func (c *Client) SendFail(command, code, context, description string) {
+	message := ":" + utils.SERVER_NAME + " FAIL " + command + " " + code
+	if context != "" {
+		message += " " + context
+	}
+	c.Send(message + " :" + description)
+}
+
 func (c *Client) JoinChannel(channel *Channel) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -118,8 +150,8 @@ func (c *Client) GetHost() string {
 
 	cfg := config.Get()
 
-	if cfg.Security.MaskHosts {
-		return c.MHost + ".irc"
+	if cfg.Security.MaskHosts && c.MHost != "" {
+		return c.MHost
 	}
 
 	return c.Host
@@ -162,6 +194,65 @@ func (c *Client) IsAway() bool {
 	return c.isAway
 }
 
+// SetAccount records the account the client has authenticated as and
+// restores the SILENCE list saved for it. An empty name logs the client out.
+func (c *Client) SetAccount(name string) {
+	c.mu.Lock()
+	c.account = name
+	c.mu.Unlock()
+
+	c.server.restoreSilence(c)
+}
+
+func (c *Client) Account() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.account
+}
+
+func (c *Client) IsLoggedIn() bool {
+	return c.Account() != ""
+}
+
+func (c *Client) SetUserMode(mode rune, enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if enabled {
+		c.userModes[mode] = true
+	} else {
+		delete(c.userModes, mode)
+	}
+}
+
+func (c *Client) HasUserMode(mode rune) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.userModes[mode]
+}
+
+// BeginList marks a LIST reply as in progress, returning false if one is
+// already being sent to the client.
+func (c *Client) BeginList() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.listing {
+		return false
+	}
+	c.listing = true
+	return true
+}
+
+func (c *Client) EndList() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.listing = false
+}
+
 func (c *Client) UpdateLastPing() {
 	c.lastPing = time.Now()
 }
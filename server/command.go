@@ -8,6 +8,14 @@ type Command interface {
 	Help() string
 }
 
+// TaggedCommand is implemented by commands that relay the client-only
+// message tags (those starting with +) sent with them.
+type TaggedCommand interface {
+	Command
+
+	ExecuteTagged(client *Client, tags map[string]string, params string)
+}
+
 type CommandInitFunc func(server *Server) Command
 
 var CommandRegistry = make(map[string]CommandInitFunc)
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"goircd/config"
+	"goircd/logger"
+	"goircd/utils"
+)
+
+// maxHostnameLength is the longest resolved hostname that is accepted.
+const maxHostnameLength = 63
+
+type dnsCacheEntry struct {
+	hostname string
+	expires  time.Time
+}
+
+// dnsCache remembers lookup results by IP, including failed lookups, which
+// are stored with an empty hostname.
+type dnsCache struct {
+	entries map[string]dnsCacheEntry
+	mu      sync.Mutex
+}
+
+func newDNSCache() *dnsCache {
+	return &dnsCache{entries: make(map[string]dnsCacheEntry)}
+}
+
+func (c *dnsCache) get(ip string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[ip]
+	if !exists {
+		return "", false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, ip)
+		return "", false
+	}
+	return entry.hostname, true
+}
+
+func (c *dnsCache) put(ip, hostname string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[ip] = dnsCacheEntry{hostname: hostname, expires: time.Now().Add(ttl)}
+}
+
+// lookupHostname starts resolving the client's hostname in the background.
+// Registration waits for it to finish and then applies the result; see
+// CompleteRegistration.
+func (s *Server) lookupHostname(client *Client) {
+	cfg := config.Get()
+	if !cfg.DNS.Enabled {
+		return
+	}
+
+	client.lookups.Add(1)
+	go func() {
+		defer client.lookups.Done()
+
+		client.SendAuthNotice("*** Looking up your hostname...")
+
+		ip := client.IP
+		hostname, cached := s.dnsCache.get(ip)
+		if !cached {
+			var reason string
+			hostname, reason = resolveHostname(ip, cfg.DNS)
+			if reason != "" {
+				logger.Debug("DNS: %s: %s", ip, reason)
+			}
+			s.dnsCache.put(ip, hostname, time.Duration(cfg.DNS.CacheTTL)*time.Second)
+		}
+
+		if hostname == "" {
+			client.SendAuthNotice("*** Couldn't look up your hostname")
+			return
+		}
+
+		client.resolvedHost = hostname
+		if cached {
+			client.SendAuthNotice("*** Found your hostname (cached)")
+		} else {
+			client.SendAuthNotice("*** Found your hostname")
+		}
+	}()
+}
+
+// resolveHostname looks up the PTR record for ip and only accepts a name
+// that resolves back to the same address. On failure it returns an empty
+// hostname and the reason.
+func resolveHostname(ip string, cfg config.DNSConfig) (string, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timeout)*time.Second)
+	defer cancel()
+
+	resolver := newResolver(cfg.Resolver)
+
+	names, err := resolver.LookupAddr(ctx, ip)
+	if err != nil || len(names) == 0 {
+		return "", "no PTR record"
+	}
+
+	hostname := strings.TrimSuffix(names[0], ".")
+	if len(hostname) > maxHostnameLength || utils.IsIPAddress(hostname) || !utils.IsValidHostname(hostname) {
+		return "", "invalid hostname " + hostname
+	}
+
+	addrs, err := resolver.LookupHost(ctx, hostname)
+	if err != nil {
+		return "", "forward lookup of " + hostname + " failed"
+	}
+
+	for _, addr := range addrs {
+		if net.ParseIP(addr).Equal(net.ParseIP(ip)) {
+			return hostname, ""
+		}
+	}
+
+	return "", "forward lookup of " + hostname + " does not match"
+}
+
+// newResolver returns a resolver that queries address, or the system
+// resolver when address is empty.
+func newResolver(address string) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
@@ -0,0 +1,347 @@
+package server
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FloodAction int
+
+const (
+	FloodActionQuiet FloodAction = iota
+	FloodActionKick
+	FloodActionBan
+	FloodActionModerate
+)
+
+var floodActionNames = map[FloodAction]string{
+	FloodActionQuiet:    "quiet",
+	FloodActionKick:     "kick",
+	FloodActionBan:      "ban",
+	FloodActionModerate: "moderate",
+}
+
+func (a FloodAction) String() string {
+	return floodActionNames[a]
+}
+
+const defaultFloodExpire = 5 * time.Minute
+
+// FloodRule allows up to Count events within Window.
+type FloodRule struct {
+	Count  int
+	Window time.Duration
+}
+
+func (r FloodRule) enabled() bool {
+	return r.Count > 0 && r.Window > 0
+}
+
+// FloodSettings is the parsed parameter of the +F channel mode, e.g.
+// "msg:5:3,repeat:3:60,chan:20:5,nick:5:30,join:4:60,action:quiet,expire:300".
+//
+// msg, repeat and join are tracked per user and trigger Action against the
+// offender. chan and nick are tracked across the whole channel: a message
+// storm sets +m and a nick storm locks nick changes, both until Expire.
+type FloodSettings struct {
+	Messages    FloodRule
+	Repeats     FloodRule
+	JoinParts   FloodRule
+	Channel     FloodRule
+	NickChanges FloodRule
+	Action      FloodAction
+	Expire      time.Duration
+}
+
+func ParseFloodSettings(value string) (*FloodSettings, error) {
+	settings := &FloodSettings{
+		Action: FloodActionQuiet,
+		Expire: defaultFloodExpire,
+	}
+
+	hasRule := false
+	for _, item := range strings.Split(value, ",") {
+		fields := strings.Split(item, ":")
+		name := strings.ToLower(fields[0])
+
+		switch name {
+		case "msg", "repeat", "join", "chan", "nick":
+			if len(fields) != 3 {
+				return nil, errors.New(name + " expects <count>:<seconds>")
+			}
+
+			count, errCount := strconv.Atoi(fields[1])
+			seconds, errSeconds := strconv.Atoi(fields[2])
+			if errCount != nil || errSeconds != nil || count <= 0 || seconds <= 0 {
+				return nil, errors.New(name + " expects positive <count>:<seconds>")
+			}
+
+			rule := FloodRule{Count: count, Window: time.Duration(seconds) * time.Second}
+			switch name {
+			case "msg":
+				settings.Messages = rule
+			case "repeat":
+				settings.Repeats = rule
+			case "join":
+				settings.JoinParts = rule
+			case "chan":
+				settings.Channel = rule
+			case "nick":
+				settings.NickChanges = rule
+			}
+			hasRule = true
+
+		case "action":
+			if len(fields) != 2 {
+				return nil, errors.New("action expects quiet, kick, ban or moderate")
+			}
+
+			found := false
+			for action, actionName := range floodActionNames {
+				if strings.ToLower(fields[1]) == actionName {
+					settings.Action = action
+					found = true
+				}
+			}
+			if !found {
+				return nil, errors.New("action expects quiet, kick, ban or moderate")
+			}
+
+		case "expire":
+			if len(fields) != 2 {
+				return nil, errors.New("expire expects <seconds>")
+			}
+
+			seconds, err := strconv.Atoi(fields[1])
+			if err != nil || seconds <= 0 {
+				return nil, errors.New("expire expects positive <seconds>")
+			}
+			settings.Expire = time.Duration(seconds) * time.Second
+
+		default:
+			return nil, errors.New("unknown flood setting " + fields[0])
+		}
+	}
+
+	if !hasRule {
+		return nil, errors.New("at least one of msg, repeat, join, chan or nick is required")
+	}
+
+	return settings, nil
+}
+
+func (f *FloodSettings) String() string {
+	var parts []string
+
+	rules := []struct {
+		name string
+		rule FloodRule
+	}{
+		{"msg", f.Messages},
+		{"repeat", f.Repeats},
+		{"join", f.JoinParts},
+		{"chan", f.Channel},
+		{"nick", f.NickChanges},
+	}
+
+	for _, r := range rules {
+		if r.rule.enabled() {
+			parts = append(parts, r.name+":"+strconv.Itoa(r.rule.Count)+":"+strconv.Itoa(int(r.rule.Window.Seconds())))
+		}
+	}
+
+	parts = append(parts, "action:"+f.Action.String(), "expire:"+strconv.Itoa(int(f.Expire.Seconds())))
+
+	return strings.Join(parts, ",")
+}
+
+type FloodViolation int
+
+const (
+	FloodNone FloodViolation = iota
+	FloodUser
+	FloodChannel
+)
+
+type repeatTracker struct {
+	text  string
+	times []time.Time
+}
+
+type floodState struct {
+	userMessages    map[*Client][]time.Time
+	userRepeats     map[*Client]*repeatTracker
+	channelMessages []time.Time
+	nickChanges     []time.Time
+	joinParts       map[string][]time.Time
+	nickLockUntil   time.Time
+	floodModerated  bool
+}
+
+func newFloodState() *floodState {
+	return &floodState{
+		userMessages: make(map[*Client][]time.Time),
+		userRepeats:  make(map[*Client]*repeatTracker),
+		joinParts:    make(map[string][]time.Time),
+	}
+}
+
+func (fs *floodState) forget(client *Client) {
+	delete(fs.userMessages, client)
+	delete(fs.userRepeats, client)
+}
+
+// pruneTimes drops the timestamps that have fallen out of window.
+func pruneTimes(times []time.Time, window time.Duration) []time.Time {
+	cutoff := time.Now().Add(-window)
+
+	kept := times[:0]
+	for _, t := range times {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// recordEvent appends now to times and reports whether rule is exceeded.
+func recordEvent(times []time.Time, rule FloodRule) ([]time.Time, bool) {
+	times = append(pruneTimes(times, rule.Window), time.Now())
+	return times, len(times) > rule.Count
+}
+
+// RecordMessageFlood accounts a channel message from client against the +F
+// settings and reports which limit, if any, it broke.
+func (ch *Channel) RecordMessageFlood(client *Client, text string) FloodViolation {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	settings := ch.Flood
+	if settings == nil {
+		return FloodNone
+	}
+
+	violation := FloodNone
+	state := ch.flood
+
+	if settings.Messages.enabled() {
+		var exceeded bool
+		state.userMessages[client], exceeded = recordEvent(state.userMessages[client], settings.Messages)
+		if exceeded {
+			violation = FloodUser
+		}
+	}
+
+	if settings.Repeats.enabled() {
+		tracker, exists := state.userRepeats[client]
+		if !exists || !strings.EqualFold(tracker.text, text) {
+			tracker = &repeatTracker{text: text}
+			state.userRepeats[client] = tracker
+		}
+
+		var exceeded bool
+		tracker.times, exceeded = recordEvent(tracker.times, settings.Repeats)
+		if exceeded {
+			violation = FloodUser
+		}
+	}
+
+	if settings.Channel.enabled() {
+		var exceeded bool
+		state.channelMessages, exceeded = recordEvent(state.channelMessages, settings.Channel)
+		if exceeded && violation == FloodNone {
+			violation = FloodChannel
+		}
+	}
+
+	return violation
+}
+
+// RecordJoinPartFlood accounts a join or part by client and reports whether
+// they have exceeded the join/part cycling limit.
+func (ch *Channel) RecordJoinPartFlood(client *Client) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.Flood == nil || !ch.Flood.JoinParts.enabled() {
+		return false
+	}
+
+	key := kickKey(client)
+
+	var exceeded bool
+	ch.flood.joinParts[key], exceeded = recordEvent(ch.flood.joinParts[key], ch.Flood.JoinParts)
+
+	for other, times := range ch.flood.joinParts {
+		if len(pruneTimes(times, ch.Flood.JoinParts.Window)) == 0 {
+			delete(ch.flood.joinParts, other)
+		}
+	}
+
+	return exceeded
+}
+
+// RecordNickChangeFlood accounts a nick change by a member. When the limit
+// is exceeded nick changes are locked for the +F expiry time and true is
+// returned.
+func (ch *Channel) RecordNickChangeFlood() bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.Flood == nil || !ch.Flood.NickChanges.enabled() {
+		return false
+	}
+
+	var exceeded bool
+	ch.flood.nickChanges, exceeded = recordEvent(ch.flood.nickChanges, ch.Flood.NickChanges)
+	if exceeded {
+		ch.flood.nickChanges = nil
+		ch.flood.nickLockUntil = time.Now().Add(ch.Flood.Expire)
+	}
+
+	return exceeded
+}
+
+func (ch *Channel) IsNickChangeLocked() bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return time.Now().Before(ch.flood.nickLockUntil)
+}
+
+// SetFloodModerated records whether +m was set by flood protection, so that
+// it is only lifted automatically if flood protection set it.
+func (ch *Channel) SetFloodModerated(moderated bool) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.flood.floodModerated = moderated
+}
+
+func (ch *Channel) IsFloodModerated() bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.flood.floodModerated
+}
+
+func (ch *Channel) GetFloodSettings() *FloodSettings {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.Flood
+}
+
+// SetFloodSettings replaces the +F settings, resetting all tracked counts.
+// A nil value disables flood protection.
+func (ch *Channel) SetFloodSettings(settings *FloodSettings) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.Flood = settings
+	moderated := ch.flood.floodModerated
+	ch.flood = newFloodState()
+	ch.flood.floodModerated = moderated
+}
@@ -0,0 +1,456 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"goircd/config"
+	"goircd/logger"
+	"goircd/utils"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type HistoryEntry struct {
+	MsgID   string    `json:"msgid"`
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Command string    `json:"command"`
+	Target  string    `json:"target"`
+	Text    string    `json:"text"`
+	Bot     bool      `json:"bot,omitempty"`
+}
+
+// HistorySelector identifies a point in a conversation either by message ID
+// or by timestamp. A zero selector stands for "*".
+type HistorySelector struct {
+	MsgID string
+	Time  time.Time
+}
+
+func (sel HistorySelector) IsZero() bool {
+	return sel.MsgID == "" && sel.Time.IsZero()
+}
+
+type HistoryTarget struct {
+	Name string
+	Time time.Time
+}
+
+type historyBuffer struct {
+	entries []HistoryEntry
+	next    int
+	full    bool
+}
+
+func newHistoryBuffer(size int) *historyBuffer {
+	return &historyBuffer{entries: make([]HistoryEntry, size)}
+}
+
+func (b *historyBuffer) add(entry HistoryEntry) {
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// items returns the buffered entries in chronological order, skipping any
+// that are older than cutoff.
+func (b *historyBuffer) items(cutoff time.Time) []HistoryEntry {
+	var ordered []HistoryEntry
+	if b.full {
+		ordered = append(ordered, b.entries[b.next:]...)
+	}
+	ordered = append(ordered, b.entries[:b.next]...)
+
+	for i, entry := range ordered {
+		if !entry.Time.Before(cutoff) {
+			return ordered[i:]
+		}
+	}
+
+	return nil
+}
+
+type HistoryStore struct {
+	buffers     map[string]*historyBuffer
+	diskLines   map[string]int
+	maxMessages int
+	maxAge      time.Duration
+	directory   string
+	mu          sync.Mutex
+}
+
+func NewHistoryStore(cfg config.HistoryConfig) *HistoryStore {
+	store := &HistoryStore{
+		buffers:     make(map[string]*historyBuffer),
+		diskLines:   make(map[string]int),
+		maxMessages: cfg.MaxMessages,
+		maxAge:      time.Duration(cfg.MaxAge) * time.Second,
+	}
+
+	if cfg.Persist {
+		if err := os.MkdirAll(cfg.Directory, 0755); err != nil {
+			logger.Error("Failed to create history directory %s: %v", cfg.Directory, err)
+		} else {
+			store.directory = cfg.Directory
+			store.loadAll()
+		}
+	}
+
+	return store
+}
+
+// ChannelHistoryKey returns the key under which a channel's history is stored.
+func ChannelHistoryKey(channel string) string {
+	return strings.ToLower(channel)
+}
+
+// NewHistoryEntry stamps a message from sender with a new message ID and
+// the current time. The same entry is used for live delivery and history,
+// so both carry the same msgid.
+func NewHistoryEntry(sender *Client, command, target, text string) HistoryEntry {
+	return HistoryEntry{
+		MsgID:   utils.GenerateMsgID(),
+		Time:    time.Now().UTC(),
+		Source:  sender.Prefix(),
+		Command: command,
+		Target:  target,
+		Text:    text,
+		Bot:     sender.HasUserMode(utils.USER_MODE_BOT),
+	}
+}
+
+// Tags returns the message tags sent with the entry.
+func (e HistoryEntry) Tags() map[string]string {
+	tags := map[string]string{
+		"time":  utils.FormatServerTime(e.Time),
+		"msgid": e.MsgID,
+	}
+	if e.Bot {
+		tags["bot"] = ""
+	}
+	return tags
+}
+
+func (h *HistoryStore) Add(key string, entry HistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.buffer(key).add(entry)
+
+	if h.directory != "" {
+		h.appendToDisk(key, entry)
+	}
+}
+
+// appendToDisk adds entry to the conversation's file. Once the file holds
+// twice as many lines as are retained, it is rewritten with only the
+// entries still within retention, so it does not grow without bound.
+func (h *HistoryStore) appendToDisk(key string, entry HistoryEntry) {
+	path := h.historyFile(key)
+
+	h.diskLines[key]++
+	if h.diskLines[key] > 2*h.maxMessages {
+		if err := h.compactFile(key, path); err != nil {
+			logger.Error("Failed to compact history file %s: %v", path, err)
+		}
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logger.Error("Failed to open history file for %s: %v", key, err)
+		return
+	}
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(entry); err != nil {
+		logger.Error("Failed to write history for %s: %v", key, err)
+	}
+}
+
+func (h *HistoryStore) buffer(key string) *historyBuffer {
+	buffer, exists := h.buffers[key]
+	if !exists {
+		buffer = newHistoryBuffer(h.maxMessages)
+		h.buffers[key] = buffer
+	}
+	return buffer
+}
+
+func (h *HistoryStore) cutoff() time.Time {
+	if h.maxAge <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-h.maxAge)
+}
+
+func (h *HistoryStore) items(key string) []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	buffer, exists := h.buffers[key]
+	if !exists {
+		return nil
+	}
+	return buffer.items(h.cutoff())
+}
+
+// position returns the index of the first entry at or after the selector,
+// and whether the selector matched an exact message ID.
+func position(entries []HistoryEntry, sel HistorySelector) (int, bool) {
+	if sel.MsgID != "" {
+		for i, entry := range entries {
+			if entry.MsgID == sel.MsgID {
+				return i, true
+			}
+		}
+		return -1, false
+	}
+
+	return sort.Search(len(entries), func(i int) bool {
+		return !entries[i].Time.Before(sel.Time)
+	}), false
+}
+
+func lastN[T any](items []T, limit int) []T {
+	if len(items) > limit {
+		return items[len(items)-limit:]
+	}
+	return items
+}
+
+func firstN[T any](items []T, limit int) []T {
+	if len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
+
+// Latest returns up to limit of the most recent entries, restricted to those
+// after sel unless sel is zero.
+func (h *HistoryStore) Latest(key string, sel HistorySelector, limit int) []HistoryEntry {
+	entries := h.items(key)
+	if sel.IsZero() {
+		return lastN(entries, limit)
+	}
+	return h.after(entries, sel, limit, true)
+}
+
+func (h *HistoryStore) Before(key string, sel HistorySelector, limit int) []HistoryEntry {
+	entries := h.items(key)
+	idx, _ := position(entries, sel)
+	if idx < 0 {
+		return nil
+	}
+	return lastN(entries[:idx], limit)
+}
+
+func (h *HistoryStore) After(key string, sel HistorySelector, limit int) []HistoryEntry {
+	return h.after(h.items(key), sel, limit, false)
+}
+
+func (h *HistoryStore) after(entries []HistoryEntry, sel HistorySelector, limit int, newest bool) []HistoryEntry {
+	idx, exact := position(entries, sel)
+	if idx < 0 {
+		return nil
+	}
+	if exact {
+		idx++
+	} else {
+		for idx < len(entries) && entries[idx].Time.Equal(sel.Time) {
+			idx++
+		}
+	}
+
+	if newest {
+		return lastN(entries[idx:], limit)
+	}
+	return firstN(entries[idx:], limit)
+}
+
+// Around returns up to limit entries centred on sel.
+func (h *HistoryStore) Around(key string, sel HistorySelector, limit int) []HistoryEntry {
+	entries := h.items(key)
+	idx, _ := position(entries, sel)
+	if idx < 0 {
+		return nil
+	}
+
+	start := idx - limit/2
+	if start < 0 {
+		start = 0
+	}
+	end := start + limit
+	if end > len(entries) {
+		end = len(entries)
+		start = max(0, end-limit)
+	}
+
+	return entries[start:end]
+}
+
+// Between returns entries strictly between the two selectors. When from is
+// later than to, the entries closest to from are returned instead.
+func (h *HistoryStore) Between(key string, from, to HistorySelector, limit int) []HistoryEntry {
+	entries := h.items(key)
+	fromIdx, fromExact := position(entries, from)
+	toIdx, toExact := position(entries, to)
+	if fromIdx < 0 || toIdx < 0 {
+		return nil
+	}
+
+	reverse := fromIdx > toIdx || (fromIdx == toIdx && from.Time.After(to.Time))
+	if reverse {
+		fromIdx, toIdx = toIdx, fromIdx
+		fromExact = toExact
+	}
+	if fromExact {
+		fromIdx++
+	}
+	if fromIdx > toIdx {
+		return nil
+	}
+
+	if reverse {
+		return lastN(entries[fromIdx:toIdx], limit)
+	}
+	return firstN(entries[fromIdx:toIdx], limit)
+}
+
+// Targets returns the channels accepted by allowChannel that have activity
+// between from and to, ordered by the time of their latest message.
+func (h *HistoryStore) Targets(allowChannel func(string) bool, from, to time.Time, limit int) []HistoryTarget {
+	if from.After(to) {
+		from, to = to, from
+	}
+
+	h.mu.Lock()
+	keys := make([]string, 0, len(h.buffers))
+	for key := range h.buffers {
+		keys = append(keys, key)
+	}
+	h.mu.Unlock()
+
+	var targets []HistoryTarget
+	for _, key := range keys {
+		if !allowChannel(key) {
+			continue
+		}
+
+		entries := h.items(key)
+		for i := len(entries) - 1; i >= 0; i-- {
+			entry := entries[i]
+			if !entry.Time.After(from) || !entry.Time.Before(to) {
+				continue
+			}
+
+			targets = append(targets, HistoryTarget{Name: entry.Target, Time: entry.Time})
+			break
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Time.Before(targets[j].Time)
+	})
+
+	return firstN(targets, limit)
+}
+
+func (h *HistoryStore) historyFile(key string) string {
+	return filepath.Join(h.directory, url.PathEscape(key)+".jsonl")
+}
+
+// loadAll reads every persisted conversation back into memory and rewrites
+// each file with only the entries that are still within retention.
+func (h *HistoryStore) loadAll() {
+	files, err := filepath.Glob(filepath.Join(h.directory, "*.jsonl"))
+	if err != nil {
+		logger.Error("Failed to list history files: %v", err)
+		return
+	}
+
+	for _, path := range files {
+		key, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(path), ".jsonl"))
+		if err != nil {
+			continue
+		}
+
+		// Private conversations are no longer kept; drop any left behind
+		// by earlier versions.
+		if strings.Contains(key, ",") {
+			os.Remove(path)
+			continue
+		}
+
+		if err := h.loadFile(key, path); err != nil {
+			logger.Error("Failed to load history file %s: %v", path, err)
+		}
+	}
+}
+
+func (h *HistoryStore) loadFile(key, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	buffer := h.buffer(key)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), 64*1024)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		buffer.add(entry)
+	}
+	file.Close()
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return h.compactFile(key, path)
+}
+
+// compactFile rewrites the conversation's file with the entries that are
+// still buffered and within the age limit, removing it if none are left.
+func (h *HistoryStore) compactFile(key, path string) error {
+	var retained []HistoryEntry
+	if buffer, exists := h.buffers[key]; exists {
+		retained = buffer.items(h.cutoff())
+	}
+
+	if len(retained) == 0 {
+		delete(h.buffers, key)
+		delete(h.diskLines, key)
+		return os.Remove(path)
+	}
+
+	tmpPath := path + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	for _, entry := range retained {
+		if err := encoder.Encode(entry); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	h.diskLines[key] = len(retained)
+	return os.Rename(tmpPath, path)
+}
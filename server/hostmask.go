@@ -0,0 +1,84 @@
+package server
+
+import (
+	"goircd/config"
+	"goircd/hash"
+	"goircd/utils"
+)
+
+// cloakHost sets the client's cloak from its host under the current secret.
+// Bans may have been set on a cloak made with an old secret, or on the cloak
+// of the client's IP before its hostname was known, so those are kept too.
+func (c *Client) cloakHost() {
+	cfg := config.Get()
+
+	c.MHost = ""
+	c.altCloaks = nil
+
+	if !cfg.Security.MaskHosts {
+		return
+	}
+
+	c.MHost = hash.Cloak(c.Host, cfg.Security.Secret)
+	for _, secret := range cfg.Security.OldSecrets {
+		c.altCloaks = append(c.altCloaks, hash.Cloak(c.Host, secret))
+	}
+
+	if c.Host != c.IP {
+		for _, secret := range append([]string{cfg.Security.Secret}, cfg.Security.OldSecrets...) {
+			c.altCloaks = append(c.altCloaks, hash.Cloak(c.IP, secret))
+		}
+	}
+}
+
+// applyHostname replaces the client's IP with the hostname found by
+// lookupHostname, if any. It is only called once the lookups have finished,
+// so the host never changes while another goroutine may be reading it.
+func (c *Client) applyHostname() {
+	if c.resolvedHost == "" {
+		return
+	}
+
+	c.Host = c.resolvedHost
+	c.cloakHost()
+}
+
+// Prefix returns the nick!user@host prefix that other users see for c.
+func (c *Client) Prefix() string {
+	return utils.FormatUserMask(c.Nick, c.User, c.GetHost())
+}
+
+// HostFor returns the host of c as shown to viewer. The client themselves
+// and IRC operators see the real host; everyone else sees the public one.
+func (c *Client) HostFor(viewer *Client) string {
+	if viewer == c || viewer.IsOperator() {
+		return c.Host
+	}
+	return c.GetHost()
+}
+
+// PrefixFor is Prefix as shown to viewer.
+func (c *Client) PrefixFor(viewer *Client) string {
+	return utils.FormatUserMask(c.Nick, c.User, c.HostFor(viewer))
+}
+
+// HasHiddenHost reports whether other users see a host other than the real
+// one, because of host masking or a vhost.
+func (c *Client) HasHiddenHost() bool {
+	return c.GetHost() != c.Host
+}
+
+// MatchesMask reports whether a nick!user@host mask matches c by its public
+// host, its real hostname or IP, or any of its other cloaks, so that bans set
+// on a cloak and bans set on an address both apply.
+func (c *Client) MatchesMask(mask string) bool {
+	hosts := append([]string{c.GetHost(), c.Host, c.IP}, c.altCloaks...)
+
+	for _, host := range hosts {
+		if utils.MatchesBanMask(utils.FormatMask(c.Nick, c.User, host), mask) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"goircd/config"
+	"goircd/utils"
+)
+
+// identPort is the port of the RFC 1413 identification service.
+const identPort = 113
+
+// lookupIdent starts an ident query for the client in the background,
+// alongside the hostname lookup. Registration waits for it to finish.
+func (s *Server) lookupIdent(client *Client) {
+	cfg := config.Get()
+	if !cfg.Server.Ident {
+		return
+	}
+
+	client.identChecked = true
+	client.lookups.Add(1)
+	go func() {
+		defer client.lookups.Done()
+
+		client.SendAuthNotice("*** Checking Ident")
+
+		username, err := queryIdent(client.conn, time.Duration(cfg.Server.IdentTimeout)*time.Second)
+		if err != nil {
+			client.SendAuthNotice("*** No Ident response")
+			return
+		}
+
+		client.identUser = username
+		client.SendAuthNotice("*** Got Ident response")
+	}()
+}
+
+// queryIdent asks the ident service on the client's host which user owns
+// the connection conn.
+func queryIdent(conn net.Conn, timeout time.Duration) (string, error) {
+	local, ok := conn.LocalAddr().(*net.TCPAddr)
+	remote, ok2 := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok || !ok2 {
+		return "", errors.New("not a TCP connection")
+	}
+
+	dialer := net.Dialer{
+		LocalAddr: &net.TCPAddr{IP: local.IP},
+		Timeout:   timeout,
+	}
+	identConn, err := dialer.Dial("tcp", net.JoinHostPort(remote.IP.String(), fmt.Sprint(identPort)))
+	if err != nil {
+		return "", err
+	}
+	defer identConn.Close()
+
+	identConn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := fmt.Fprintf(identConn, "%d, %d\r\n", remote.Port, local.Port); err != nil {
+		return "", err
+	}
+
+	reader := bufio.NewReaderSize(identConn, 512)
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	return parseIdentReply(line)
+}
+
+// parseIdentReply extracts the user ID from a reply of the form
+// "<ports> : USERID : <os> : <user id>".
+func parseIdentReply(line string) (string, error) {
+	parts := strings.SplitN(strings.TrimSpace(line), ":", 4)
+	if len(parts) != 4 || strings.TrimSpace(parts[1]) != "USERID" {
+		return "", errors.New("no user ID in ident reply")
+	}
+
+	username := strings.TrimSpace(parts[3])
+	if username == "" || strings.ContainsAny(username, " !@:\x00") {
+		return "", errors.New("invalid user ID in ident reply")
+	}
+
+	return username, nil
+}
+
+// applyIdent sets the client's username from its ident reply. When ident is
+// enabled but gave no answer, the username from USER is kept with a ~ to
+// show that it is unverified.
+func (c *Client) applyIdent() {
+	username := c.User
+	if c.identUser != "" {
+		username = c.identUser
+	} else if c.identChecked {
+		username = "~" + username
+	}
+
+	if len(username) > utils.MAX_USERNAME_LENGTH {
+		username = username[:utils.MAX_USERNAME_LENGTH]
+	}
+	c.User = username
+}
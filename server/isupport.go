@@ -0,0 +1,52 @@
+package server
+
+import (
+	"goircd/config"
+	"goircd/utils"
+	"strconv"
+	"strings"
+)
+
+const maxISupportTokensPerLine = 13
+
+func isupportTokens() []string {
+	cfg := config.Get()
+
+	tokens := []string{
+		"CHANTYPES=#&",
+		"PREFIX=(ov)@+",
+		"CHANMODES=" + ChanModesToken(),
+		"MODES=" + strconv.Itoa(utils.MAX_MODE_PARAMS),
+		"TARGMAX=PRIVMSG:" + strconv.Itoa(utils.MAX_MESSAGE_TARGETS) + ",NOTICE:" + strconv.Itoa(utils.MAX_MESSAGE_TARGETS) + ",TAGMSG:" + strconv.Itoa(utils.MAX_MESSAGE_TARGETS),
+		"CHANNELLEN=" + strconv.Itoa(cfg.Security.MaxChannelName),
+		"CHANLIMIT=#&:" + strconv.Itoa(cfg.Channels.MaxChannels),
+		"NICKLEN=" + strconv.Itoa(cfg.Security.MaxNickLength),
+		"TOPICLEN=" + strconv.Itoa(utils.MAX_TOPIC_LENGTH),
+		"USERLEN=" + strconv.Itoa(utils.MAX_USERNAME_LENGTH),
+		"STATUSMSG=" + utils.STATUSMSG,
+		"BOT=" + string(utils.USER_MODE_BOT),
+		"CALLERID=" + string(utils.USER_MODE_CALLERID),
+		"ELIST=CMNTU",
+		"MONITOR=" + strconv.Itoa(cfg.Users.MonitorLimit),
+		"SILENCE=" + strconv.Itoa(cfg.Users.SilenceLimit),
+		"SAFELIST",
+	}
+
+	if cfg.History.Enabled {
+		tokens = append(tokens,
+			"CHATHISTORY="+strconv.Itoa(cfg.History.QueryLimit),
+			"MSGREFTYPES=timestamp,msgid",
+		)
+	}
+
+	return tokens
+}
+
+func sendISupport(client *Client) {
+	tokens := isupportTokens()
+
+	for start := 0; start < len(tokens); start += maxISupportTokensPerLine {
+		end := min(start+maxISupportTokensPerLine, len(tokens))
+		client.SendNumeric(utils.RPL_ISUPPORT, strings.Join(tokens[start:end], " ")+" :are supported by this server")
+	}
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"goircd/utils"
+	"sort"
+	"time"
+)
+
+// ListEntry is a ban, exception or quiet mask along with who set it and
+// when it lapses. A zero Expires means the entry is permanent.
+type ListEntry struct {
+	Mask    string
+	SetBy   string
+	SetAt   time.Time
+	Expires time.Time
+}
+
+func (e *ListEntry) IsExpired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// ExpiredEntry identifies a list entry removed by ExpireListEntries.
+type ExpiredEntry struct {
+	Mode rune
+	Mask string
+}
+
+type maskList map[string]*ListEntry
+
+func (l maskList) add(mask, setBy string, duration time.Duration) {
+	entry := &ListEntry{
+		Mask:  mask,
+		SetBy: setBy,
+		SetAt: time.Now(),
+	}
+	if duration > 0 {
+		entry.Expires = entry.SetAt.Add(duration)
+	}
+	l[mask] = entry
+}
+
+func (l maskList) remove(mask string) bool {
+	_, exists := l[mask]
+	delete(l, mask)
+	return exists
+}
+
+func (l maskList) matches(client *Client) bool {
+	now := time.Now()
+
+	for mask, entry := range l {
+		if !entry.IsExpired(now) && client.MatchesMask(mask) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// entries returns a copy of the list ordered by the time each entry was set.
+func (l maskList) entries() []ListEntry {
+	entries := make([]ListEntry, 0, len(l))
+	for _, entry := range l {
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].SetAt.Before(entries[j].SetAt)
+	})
+
+	return entries
+}
+
+func (l maskList) expire(mode rune, now time.Time) []ExpiredEntry {
+	var expired []ExpiredEntry
+	for mask, entry := range l {
+		if entry.IsExpired(now) {
+			delete(l, mask)
+			expired = append(expired, ExpiredEntry{Mode: mode, Mask: mask})
+		}
+	}
+	return expired
+}
+
+func (ch *Channel) AddBan(mask, setBy string, duration time.Duration) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.banned.add(mask, setBy, duration)
+}
+
+func (ch *Channel) RemoveBan(mask string) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return ch.banned.remove(mask)
+}
+
+func (ch *Channel) HasBan(mask string) bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	_, exists := ch.banned[mask]
+	return exists
+}
+
+// IsBanned reports whether client matches a ban and no ban exception.
+func (ch *Channel) IsBanned(client *Client) bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.banned.matches(client) && !ch.excepted.matches(client)
+}
+
+func (ch *Channel) GetBanList() []ListEntry {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.banned.entries()
+}
+
+func (ch *Channel) AddException(mask, setBy string, duration time.Duration) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.excepted.add(mask, setBy, duration)
+}
+
+func (ch *Channel) RemoveException(mask string) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return ch.excepted.remove(mask)
+}
+
+func (ch *Channel) GetExceptionList() []ListEntry {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.excepted.entries()
+}
+
+func (ch *Channel) AddQuiet(mask, setBy string, duration time.Duration) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.quieted.add(mask, setBy, duration)
+}
+
+func (ch *Channel) RemoveQuiet(mask string) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return ch.quieted.remove(mask)
+}
+
+func (ch *Channel) HasQuiet(mask string) bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	_, exists := ch.quieted[mask]
+	return exists
+}
+
+// IsQuieted reports whether client matches a quiet and no ban exception.
+func (ch *Channel) IsQuieted(client *Client) bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.quieted.matches(client) && !ch.excepted.matches(client)
+}
+
+func (ch *Channel) GetQuietList() []ListEntry {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.quieted.entries()
+}
+
+func (ch *Channel) ListSize() int {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return len(ch.banned) + len(ch.excepted) + len(ch.quieted)
+}
+
+// ExpireListEntries removes every ban, exception and quiet that has lapsed
+// and returns what was removed.
+func (ch *Channel) ExpireListEntries(now time.Time) []ExpiredEntry {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	var expired []ExpiredEntry
+	expired = append(expired, ch.banned.expire(utils.CHAN_MODE_BAN, now)...)
+	expired = append(expired, ch.excepted.expire(utils.CHAN_MODE_EXCEPTION, now)...)
+	expired = append(expired, ch.quieted.expire(utils.CHAN_MODE_QUIET, now)...)
+
+	return expired
+}
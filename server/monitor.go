@@ -0,0 +1,151 @@
+package server
+
+import (
+	"sort"
+	"strings"
+
+	"goircd/utils"
+)
+
+// maxMonitorLineLength bounds the target list in one MONITOR reply.
+const maxMonitorLineLength = 400
+
+// AddMonitor adds nick to the client's MONITOR list. It returns false if the
+// list already holds limit entries; adding a nick already on the list
+// succeeds.
+func (s *Server) AddMonitor(client *Client, nick string, limit int) bool {
+	key := strings.ToLower(nick)
+
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if _, exists := client.monitoring[key]; exists {
+		return true
+	}
+	if len(client.monitoring) >= limit {
+		return false
+	}
+
+	client.monitoring[key] = nick
+	if s.monitors[key] == nil {
+		s.monitors[key] = make(map[*Client]bool)
+	}
+	s.monitors[key][client] = true
+	return true
+}
+
+func (s *Server) RemoveMonitor(client *Client, nick string) {
+	key := strings.ToLower(nick)
+
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+
+	client.mu.Lock()
+	delete(client.monitoring, key)
+	client.mu.Unlock()
+
+	s.unwatch(client, key)
+}
+
+// ClearMonitor empties the client's MONITOR list.
+func (s *Server) ClearMonitor(client *Client) {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+
+	client.mu.Lock()
+	keys := make([]string, 0, len(client.monitoring))
+	for key := range client.monitoring {
+		keys = append(keys, key)
+	}
+	client.monitoring = make(map[string]string)
+	client.mu.Unlock()
+
+	for _, key := range keys {
+		s.unwatch(client, key)
+	}
+}
+
+func (s *Server) unwatch(client *Client, key string) {
+	delete(s.monitors[key], client)
+	if len(s.monitors[key]) == 0 {
+		delete(s.monitors, key)
+	}
+}
+
+// GetMonitorList returns the nicks on the client's MONITOR list, sorted.
+func (c *Client) GetMonitorList() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	nicks := make([]string, 0, len(c.monitoring))
+	for _, nick := range c.monitoring {
+		nicks = append(nicks, nick)
+	}
+	sort.Strings(nicks)
+	return nicks
+}
+
+func (s *Server) monitorsOf(nick string) []*Client {
+	s.monitorMu.RLock()
+	defer s.monitorMu.RUnlock()
+
+	watchers := make([]*Client, 0, len(s.monitors[strings.ToLower(nick)]))
+	for watcher := range s.monitors[strings.ToLower(nick)] {
+		watchers = append(watchers, watcher)
+	}
+	return watchers
+}
+
+// notifyMonitorOnline tells everyone monitoring the client's nick that it
+// is now online.
+func (s *Server) notifyMonitorOnline(client *Client) {
+	for _, watcher := range s.monitorsOf(client.Nick) {
+		watcher.SendNumeric(utils.RPL_MONONLINE, ":"+client.Prefix())
+	}
+}
+
+// notifyMonitorOffline tells everyone monitoring nick that it has gone.
+func (s *Server) notifyMonitorOffline(nick string) {
+	for _, watcher := range s.monitorsOf(nick) {
+		watcher.SendNumeric(utils.RPL_MONOFFLINE, ":"+nick)
+	}
+}
+
+// SendMonitorStatus sends RPL_MONONLINE and RPL_MONOFFLINE replies to client
+// for the given nicks.
+func (s *Server) SendMonitorStatus(client *Client, nicks []string) {
+	var online, offline []string
+	for _, nick := range nicks {
+		if target := s.FindClient(nick); target != nil {
+			online = append(online, target.Prefix())
+		} else {
+			offline = append(offline, nick)
+		}
+	}
+
+	SendTargetList(client, utils.RPL_MONONLINE, online)
+	SendTargetList(client, utils.RPL_MONOFFLINE, offline)
+}
+
+// SendTargetList sends items as comma-separated lists in as few replies of
+// the given numeric as fit on a line.
+func SendTargetList(client *Client, numeric int, items []string) {
+	var line strings.Builder
+	for _, item := range items {
+		if line.Len() > 0 && line.Len()+len(item) >= maxMonitorLineLength {
+			client.SendNumeric(numeric, ":"+line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(',')
+		}
+		line.WriteString(item)
+	}
+
+	if line.Len() > 0 {
+		client.SendNumeric(numeric, ":"+line.String())
+	}
+}
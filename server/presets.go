@@ -0,0 +1,167 @@
+package server
+
+import (
+	"strconv"
+
+	"goircd/config"
+	"goircd/utils"
+)
+
+// ApplyChannelPresets creates the permanent channels described in the
+// configuration and re-applies the settings of preset channels that already
+// exist. Other presets are applied by newChannel when their channel is first
+// joined, since an empty non-permanent channel would be destroyed anyway.
+// Channels whose preset has been removed stop auto-opping and become
+// ordinary channels.
+func (s *Server) ApplyChannelPresets() {
+	cfg := config.Get()
+
+	for _, preset := range cfg.Channels.Presets {
+		if !utils.IsValidChannelName(preset.Name) {
+			continue
+		}
+
+		channel := s.GetChannel(preset.Name)
+		if channel == nil {
+			if preset.Permanent != nil && *preset.Permanent {
+				s.GetOrCreateChannel(preset.Name)
+			}
+			continue
+		}
+
+		topicChanged, modes := channel.ApplyPreset(&preset)
+		if topicChanged {
+			topic, _, _ := channel.GetTopic()
+			channel.Broadcast(":" + utils.SERVER_NAME + " TOPIC " + channel.Name + " :" + topic)
+		}
+		if len(modes) > 0 {
+			channel.Broadcast(":" + utils.SERVER_NAME + " MODE " + channel.Name + " " + utils.FormatModeString(modes))
+		}
+
+		// The preset may no longer be permanent.
+		s.DestroyChannelIfEmpty(channel)
+	}
+
+	for _, channel := range s.GetAllChannels() {
+		if channel.IsPreset() && cfg.Channels.Preset(channel.Name) == nil {
+			channel.ClearPreset()
+			s.DestroyChannelIfEmpty(channel)
+		}
+	}
+}
+
+// newChannel creates a channel, configuring it from its preset if it has one.
+// A preset channel is named as in the configuration, whatever case it was
+// joined with.
+func newChannel(name string) *Channel {
+	cfg := config.Get()
+	preset := cfg.Channels.Preset(name)
+	if preset != nil {
+		name = preset.Name
+	}
+
+	channel := NewChannel(name)
+
+	if preset != nil {
+		if preset.Modes == "" {
+			channel.ApplyDefaultModes(cfg.Channels.DefaultModes)
+		}
+		channel.ApplyPreset(preset)
+	}
+
+	return channel
+}
+
+// ApplyPreset configures the channel from preset and reports whether the
+// topic changed and which modes changed. Bans are only ever added so that
+// bans set by channel operators survive a rehash. +P is only changed when the
+// preset sets permanent, and a key or limit is only cleared when the preset
+// had set it and no longer does.
+func (ch *Channel) ApplyPreset(preset *config.ChannelPreset) (bool, []utils.Mode) {
+	topicChanged := false
+	if topic, _, _ := ch.GetTopic(); preset.Topic != "" && topic != preset.Topic {
+		ch.SetTopic(preset.Topic, utils.SERVER_NAME)
+		topicChanged = true
+	}
+
+	if preset.EntryMsg != "" {
+		ch.SetEntryMessage(preset.EntryMsg)
+	}
+
+	changes := ch.ApplyDefaultModes(preset.Modes)
+
+	if preset.Permanent != nil && ch.setModeChanged(ModePermanent, *preset.Permanent) {
+		changes = append(changes, utils.Mode{Adding: *preset.Permanent, Letter: utils.CHAN_MODE_PERMANENT})
+	}
+
+	for _, mask := range preset.Bans {
+		if !ch.HasBan(mask) {
+			ch.AddBan(mask, utils.SERVER_NAME, 0)
+			changes = append(changes, utils.Mode{Adding: true, Letter: utils.CHAN_MODE_BAN, Param: mask})
+		}
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	last := ch.lastPreset
+	if last == nil {
+		last = &config.ChannelPreset{}
+	}
+
+	switch {
+	case preset.Key != "" && ch.Key != preset.Key:
+		ch.Key = preset.Key
+		changes = append(changes, utils.Mode{Adding: true, Letter: utils.CHAN_MODE_KEY, Param: preset.Key})
+	case preset.Key == "" && last.Key != "" && ch.Key == last.Key:
+		ch.Key = ""
+		changes = append(changes, utils.Mode{Adding: false, Letter: utils.CHAN_MODE_KEY, Param: "*"})
+	}
+
+	switch {
+	case preset.Limit > 0 && ch.Limit != preset.Limit:
+		ch.Limit = preset.Limit
+		changes = append(changes, utils.Mode{Adding: true, Letter: utils.CHAN_MODE_LIMIT, Param: strconv.Itoa(preset.Limit)})
+	case preset.Limit <= 0 && last.Limit > 0 && ch.Limit == last.Limit:
+		ch.Limit = 0
+		changes = append(changes, utils.Mode{Adding: false, Letter: utils.CHAN_MODE_LIMIT})
+	}
+
+	applied := *preset
+	ch.lastPreset = &applied
+
+	ch.autoOp = preset.AutoOp
+	ch.preset = true
+
+	return topicChanged, changes
+}
+
+func (ch *Channel) ClearPreset() {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.autoOp = nil
+	ch.preset = false
+	ch.lastPreset = nil
+}
+
+func (ch *Channel) IsPreset() bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.preset
+}
+
+// IsAutoOp reports whether client matches one of the preset's auto-op masks.
+func (ch *Channel) IsAutoOp(client *Client) bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	for _, mask := range ch.autoOp {
+		if client.MatchesMask(mask) {
+			return true
+		}
+	}
+
+	return false
+}
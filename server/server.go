@@ -11,8 +11,11 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
+const listExpiryInterval = 5 * time.Second
+
 type Server struct {
 	host           string
 	port           int
@@ -26,24 +29,42 @@ type Server struct {
 	shutdownCmd    chan string
 	isShuttingDown bool
 	shutdownOnce   sync.Once
+	history        *HistoryStore
+	dnsCache       *dnsCache
+	whowas         whowasHistory
+	monitors       map[string]map[*Client]bool
+	monitorMu      sync.RWMutex
+	accountSilence map[string][]string
+	silenceMu      sync.RWMutex
 }
 
 func NewServer(host string, port int) (*Server, error) {
 	server := &Server{
-		host:        host,
-		port:        port,
-		clients:     make(map[string]*Client),
-		channels:    make(map[string]*Channel),
-		commands:    make(map[string]Command),
-		shutdown:    make(chan struct{}),
-		shutdownCmd: make(chan string, 1),
+		host:           host,
+		port:           port,
+		clients:        make(map[string]*Client),
+		channels:       make(map[string]*Channel),
+		commands:       make(map[string]Command),
+		shutdown:       make(chan struct{}),
+		shutdownCmd:    make(chan string, 1),
+		dnsCache:       newDNSCache(),
+		monitors:       make(map[string]map[*Client]bool),
+		accountSilence: make(map[string][]string),
+	}
+
+	cfg := config.Get()
+	if cfg.History.Enabled {
+		server.history = NewHistoryStore(cfg.History)
 	}
 
 	if err := server.loadCommands(); err != nil {
-		logger.Fatal("failed to load commands: %w", err)
+		logger.Fatal("failed to load commands: %v", err)
 	}
 
+	server.ApplyChannelPresets()
+
 	go server.handleShutdown()
+	go server.expireListEntries()
 
 	return server, nil
 }
@@ -52,7 +73,7 @@ func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.host, s.port)
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
-		logger.Fatal("failed to listen on %s: %w", addr, err)
+		logger.Fatal("failed to listen on %s: %v", addr, err)
 	}
 	s.listener = listener
 	logger.Info("Server listening on %s", addr)
@@ -93,6 +114,9 @@ func (s *Server) handleClient(conn net.Conn) {
 
 	logger.Info("Client connecting from %s", client.Host)
 
+	s.lookupHostname(client)
+	s.lookupIdent(client)
+
 	scanner := bufio.NewScanner(conn)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -109,6 +133,7 @@ func (s *Server) handleClient(conn net.Conn) {
 }
 
 func (s *Server) processCommand(client *Client, line string) {
+	tags, line := splitTags(line)
 	if line == "" {
 		return
 	}
@@ -124,7 +149,9 @@ func (s *Server) processCommand(client *Client, line string) {
 	cmd, exists := s.commands[cmdName]
 	s.mu.RUnlock()
 
-	if exists {
+	if tagged, ok := cmd.(TaggedCommand); ok {
+		tagged.ExecuteTagged(client, tags, params)
+	} else if exists {
 		cmd.Execute(client, params)
 	} else {
 		client.SendNumeric(421, fmt.Sprintf("%s :Unknown command", cmdName))
@@ -150,34 +177,94 @@ func (s *Server) loadCommands() error {
 func (s *Server) AddClient(client *Client) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.clients[client.Nick] = client
+	s.clients[strings.ToLower(client.Nick)] = client
 }
 
-func (s *Server) RemoveClient(client *Client) {
+// ChangeNick sets client's nickname and updates the nickname index. A
+// change of case only keeps the client under the same nick, so it leaves no
+// WHOWAS entry and does not tell MONITOR watchers the old nick went offline.
+func (s *Server) ChangeNick(client *Client, nick string) {
+	oldNick := client.Nick
+	if nick == oldNick {
+		return
+	}
+
+	caseOnly := strings.EqualFold(nick, oldNick)
+	if !caseOnly {
+		s.RecordWhowas(client)
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	if key := strings.ToLower(client.Nick); client.Nick != "" && s.clients[key] == client {
+		delete(s.clients, key)
+	}
 
-	for _, channel := range s.channels {
-		channel.RemoveClient(client)
+	client.SetNick(nick)
+	s.clients[strings.ToLower(nick)] = client
+	s.mu.Unlock()
+
+	if client.IsRegistered() {
+		if !caseOnly {
+			s.notifyMonitorOffline(oldNick)
+		}
+		s.notifyMonitorOnline(client)
 	}
+}
 
-	if client.Nick != "" {
-		delete(s.clients, client.Nick)
+func (s *Server) RemoveClient(client *Client) {
+	s.RecordWhowas(client)
+
+	for _, channel := range client.GetChannels() {
+		s.RemoveFromChannel(channel, client)
+	}
+
+	s.ClearMonitor(client)
+
+	s.mu.Lock()
+	if key := strings.ToLower(client.Nick); client.Nick != "" && s.clients[key] == client {
+		delete(s.clients, key)
+	}
+	s.mu.Unlock()
+
+	if client.IsRegistered() {
+		s.notifyMonitorOffline(client.Nick)
 	}
 
 	logger.Info("Client disconnected: %s", client.Nick)
 }
 
+// GetClient looks up a client by nickname. Nicknames are case-insensitive,
+// so Bob and bob are the same client.
 func (s *Server) GetClient(nick string) *Client {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.clients[nick]
+	return s.clients[strings.ToLower(nick)]
+}
+
+// FindClient is GetClient restricted to registered clients.
+func (s *Server) FindClient(nick string) *Client {
+	if client := s.GetClient(nick); client != nil && client.IsRegistered() {
+		return client
+	}
+	return nil
+}
+
+func (s *Server) GetAllClients() []*Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	clients := make([]*Client, 0, len(s.clients))
+	for _, client := range s.clients {
+		clients = append(clients, client)
+	}
+
+	return clients
 }
 
 func (s *Server) GetChannel(name string) *Channel {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.channels[name]
+	return s.channels[strings.ToLower(name)]
 }
 
 func (s *Server) GetAllChannels() []*Channel {
@@ -192,13 +279,71 @@ func (s *Server) GetAllChannels() []*Channel {
 	return channels
 }
 
-func (s *Server) CreateChannel(name string) *Channel {
+// GetOrCreateChannel returns the named channel, creating it if it does not
+// exist. The boolean result reports whether the channel was created.
+// Channel names are case-insensitive; a new channel keeps the case it was
+// created with.
+func (s *Server) GetOrCreateChannel(name string) (*Channel, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	channel := NewChannel(name)
-	s.channels[name] = channel
-	return channel
+	key := strings.ToLower(name)
+	if channel, exists := s.channels[key]; exists {
+		return channel, false
+	}
+
+	channel := newChannel(name)
+	s.channels[key] = channel
+	return channel, true
+}
+
+// RemoveFromChannel removes client from channel and destroys the channel if
+// that left it empty.
+func (s *Server) RemoveFromChannel(channel *Channel, client *Client) {
+	channel.RemoveClient(client)
+	s.DestroyChannelIfEmpty(channel)
+}
+
+// DestroyChannelIfEmpty forgets channel once its last member has left,
+// unless it is marked permanent (+P).
+func (s *Server) DestroyChannelIfEmpty(channel *Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(channel.Name)
+	if s.channels[key] != channel {
+		return
+	}
+
+	if channel.destroyIfEmpty() {
+		delete(s.channels, key)
+		logger.Debug("Channel %s destroyed", channel.Name)
+	}
+}
+
+// expireListEntries periodically removes lapsed bans, exceptions and quiets
+// and announces each removal to the channel.
+func (s *Server) expireListEntries() {
+	ticker := time.NewTicker(listExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.shutdown:
+			return
+		case now := <-ticker.C:
+			for _, channel := range s.GetAllChannels() {
+				for _, expired := range channel.ExpireListEntries(now) {
+					channel.Broadcast(":" + utils.SERVER_NAME + " MODE " + channel.Name + " -" + string(expired.Mode) + " " + expired.Mask)
+				}
+			}
+		}
+	}
+}
+
+// History returns the message history store, or nil if history is disabled.
+func (s *Server) History() *HistoryStore {
+	return s.history
 }
 
 func (s *Server) handleShutdown() {
@@ -259,17 +404,44 @@ func (s *Server) Shutdown() {
 	s.doShutdown()
 }
 
+// CompleteRegistration marks client as registered once both NICK and USER
+// have been received, welcomes them and joins them to the default channels
+// when auto_join is enabled. A client negotiating capabilities is not
+// registered until it sends CAP END.
+func (s *Server) CompleteRegistration(client *Client) {
+	if client.isNegotiatingCaps() {
+		return
+	}
+
+	// The hostname and ident must be known before the client is welcomed.
+	client.lookups.Wait()
+	client.applyHostname()
+	client.applyIdent()
+
+	client.SetRegistered()
+	SendWelcomeMessages(client)
+	s.notifyMonitorOnline(client)
+
+	cfg := config.Get()
+	if cfg.Channels.AutoJoin && len(cfg.Channels.DefaultChannels) > 0 {
+		s.processCommand(client, "JOIN "+strings.Join(cfg.Channels.DefaultChannels, ","))
+	}
+}
+
 func SendWelcomeMessages(client *Client) {
 	client.SendNumeric(utils.RPL_WELCOME, "Welcome to the Internet Relay Network "+
-		utils.FormatUserMask(client.Nick, client.User, client.Host))
+		client.Prefix())
 
 	client.SendNumeric(utils.RPL_YOURHOST, "Your host is "+utils.SERVER_NAME+
 		", running version "+utils.SERVER_VERSION)
 
 	client.SendNumeric(utils.RPL_CREATED, "This server was created "+utils.SERVER_CREATED)
 
+	channelModes, paramModes := ChannelModeLetters()
 	client.SendNumeric(utils.RPL_MYINFO, utils.SERVER_NAME+" "+utils.SERVER_VERSION+
-		" iwr oiw")
+		" "+utils.USER_MODES+" "+channelModes+" "+paramModes)
+
+	sendISupport(client)
 
 	cfg := config.Get()
 	if cfg.MOTD != "" {
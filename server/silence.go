@@ -0,0 +1,107 @@
+package server
+
+import (
+	"sort"
+	"strings"
+)
+
+// AddSilence adds mask to the client's SILENCE list. It returns false if the
+// mask was already on it.
+func (c *Client) AddSilence(mask string) bool {
+	c.mu.Lock()
+	key := strings.ToLower(mask)
+	if _, exists := c.silenced[key]; exists {
+		c.mu.Unlock()
+		return false
+	}
+	c.silenced[key] = mask
+	c.mu.Unlock()
+
+	c.server.saveSilence(c)
+	return true
+}
+
+// RemoveSilence removes mask from the client's SILENCE list. It returns
+// false if the mask was not on it.
+func (c *Client) RemoveSilence(mask string) bool {
+	c.mu.Lock()
+	key := strings.ToLower(mask)
+	if _, exists := c.silenced[key]; !exists {
+		c.mu.Unlock()
+		return false
+	}
+	delete(c.silenced, key)
+	c.mu.Unlock()
+
+	c.server.saveSilence(c)
+	return true
+}
+
+// GetSilenceList returns the masks on the client's SILENCE list, sorted.
+func (c *Client) GetSilenceList() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	masks := make([]string, 0, len(c.silenced))
+	for _, mask := range c.silenced {
+		masks = append(masks, mask)
+	}
+	sort.Strings(masks)
+	return masks
+}
+
+// IsSilencing reports whether the client has silenced sender. Messages from
+// IRC operators are never silenced.
+func (c *Client) IsSilencing(sender *Client) bool {
+	if sender.IsOperator() || sender == c {
+		return false
+	}
+
+	for _, mask := range c.GetSilenceList() {
+		if sender.MatchesMask(mask) {
+			return true
+		}
+	}
+	return false
+}
+
+// saveSilence keeps the SILENCE list of a logged-in client with its account,
+// so that it is restored when the account next logs in.
+func (s *Server) saveSilence(client *Client) {
+	account := strings.ToLower(client.Account())
+	if account == "" {
+		return
+	}
+
+	masks := client.GetSilenceList()
+
+	s.silenceMu.Lock()
+	defer s.silenceMu.Unlock()
+
+	if len(masks) == 0 {
+		delete(s.accountSilence, account)
+	} else {
+		s.accountSilence[account] = masks
+	}
+}
+
+// restoreSilence adds the masks saved for the client's account to its
+// SILENCE list.
+func (s *Server) restoreSilence(client *Client) {
+	account := strings.ToLower(client.Account())
+	if account == "" {
+		return
+	}
+
+	s.silenceMu.RLock()
+	masks := s.accountSilence[account]
+	s.silenceMu.RUnlock()
+
+	client.mu.Lock()
+	for _, mask := range masks {
+		client.silenced[strings.ToLower(mask)] = mask
+	}
+	client.mu.Unlock()
+
+	s.saveSilence(client)
+}
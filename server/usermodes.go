@@ -0,0 +1,126 @@
+package server
+
+import (
+	"goircd/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+// callerIDNoticeInterval limits how often a +g user is told that someone
+// they have not accepted is trying to message them.
+const callerIDNoticeInterval = time.Minute
+
+// GetUserModes returns the client's user modes as a sorted mode string
+// without the leading '+'.
+func (c *Client) GetUserModes() string {
+	c.mu.RLock()
+	modes := make([]rune, 0, len(c.userModes)+1)
+	for mode := range c.userModes {
+		modes = append(modes, mode)
+	}
+	c.mu.RUnlock()
+
+	if c.IsOperator() {
+		modes = append(modes, utils.USER_MODE_OPERATOR)
+	}
+
+	sort.Slice(modes, func(i, j int) bool {
+		return modes[i] < modes[j]
+	})
+
+	return string(modes)
+}
+
+// SharesChannelWith reports whether both clients are in at least one
+// common channel.
+func (c *Client) SharesChannelWith(other *Client) bool {
+	for _, channel := range c.GetChannels() {
+		if channel.HasClient(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsVisibleTo reports whether viewer may see the client in WHO and NAMES
+// replies. Invisible (+i) clients are only shown to themselves, to IRC
+// operators and to users sharing a channel with them.
+func (c *Client) IsVisibleTo(viewer *Client) bool {
+	if !c.HasUserMode(utils.USER_MODE_INVISIBLE) || c == viewer || viewer.IsOperator() {
+		return true
+	}
+	return c.SharesChannelWith(viewer)
+}
+
+// AddAccept adds nick to the caller-id (+g) accept list. It returns false if
+// the nick was already accepted.
+func (c *Client) AddAccept(nick string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := strings.ToLower(nick)
+	if _, exists := c.accepted[key]; exists {
+		return false
+	}
+
+	c.accepted[key] = nick
+	return true
+}
+
+func (c *Client) RemoveAccept(nick string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := strings.ToLower(nick)
+	if _, exists := c.accepted[key]; !exists {
+		return false
+	}
+
+	delete(c.accepted, key)
+	return true
+}
+
+func (c *Client) IsAccepted(nick string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, exists := c.accepted[strings.ToLower(nick)]
+	return exists
+}
+
+func (c *Client) GetAcceptList() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	nicks := make([]string, 0, len(c.accepted))
+	for _, nick := range c.accepted {
+		nicks = append(nicks, nick)
+	}
+	sort.Strings(nicks)
+
+	return nicks
+}
+
+// AcceptsMessagesFrom reports whether a private message from sender gets
+// past the client's caller-id (+g) setting.
+func (c *Client) AcceptsMessagesFrom(sender *Client) bool {
+	if !c.HasUserMode(utils.USER_MODE_CALLERID) || c == sender || sender.IsOperator() {
+		return true
+	}
+	return c.IsAccepted(sender.Nick)
+}
+
+// ShouldNotifyCallerID reports whether the client should be told about a
+// blocked message now, and if so starts a new quiet period.
+func (c *Client) ShouldNotifyCallerID() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.lastCallerIDNotice) < callerIDNoticeInterval {
+		return false
+	}
+
+	c.lastCallerIDNotice = time.Now()
+	return true
+}
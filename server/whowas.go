@@ -0,0 +1,94 @@
+package server
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"goircd/config"
+	"goircd/utils"
+)
+
+// WhowasEntry records a nickname that has left the network or been changed.
+type WhowasEntry struct {
+	Nick     string
+	User     string
+	Host     string
+	RealHost string
+	RealName string
+	Server   string
+	SignOff  time.Time
+}
+
+// whowasHistory keeps the most recent entries, oldest first, bounded both in
+// total and per nickname.
+type whowasHistory struct {
+	entries []WhowasEntry
+	mu      sync.RWMutex
+}
+
+func (h *whowasHistory) record(entry WhowasEntry, maxTotal, maxPerNick int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, entry)
+
+	// Drop the oldest entries for this nick beyond the per-nick limit.
+	count := 0
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if !strings.EqualFold(h.entries[i].Nick, entry.Nick) {
+			continue
+		}
+		count++
+		if count > maxPerNick {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+		}
+	}
+
+	if len(h.entries) > maxTotal {
+		h.entries = append([]WhowasEntry(nil), h.entries[len(h.entries)-maxTotal:]...)
+	}
+}
+
+// lookup returns up to count entries for nick, newest first. A count of zero
+// or less returns all of them.
+func (h *whowasHistory) lookup(nick string, count int) []WhowasEntry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var entries []WhowasEntry
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if count > 0 && len(entries) >= count {
+			break
+		}
+		if strings.EqualFold(h.entries[i].Nick, nick) {
+			entries = append(entries, h.entries[i])
+		}
+	}
+
+	return entries
+}
+
+// RecordWhowas remembers client under its current nickname. Clients that
+// never registered are not recorded.
+func (s *Server) RecordWhowas(client *Client) {
+	if !client.IsRegistered() || client.Nick == "" {
+		return
+	}
+
+	cfg := config.Get()
+	s.whowas.record(WhowasEntry{
+		Nick:     client.Nick,
+		User:     client.User,
+		Host:     client.GetHost(),
+		RealHost: client.Host,
+		RealName: client.RealName,
+		Server:   utils.SERVER_NAME,
+		SignOff:  time.Now(),
+	}, cfg.Users.WhowasLength, cfg.Users.WhowasPerNick)
+}
+
+// GetWhowas returns up to count past entries for nick, newest first.
+func (s *Server) GetWhowas(nick string, count int) []WhowasEntry {
+	return s.whowas.lookup(nick, count)
+}
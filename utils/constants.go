@@ -9,6 +9,7 @@ const (
 	RPL_MYINFO          = 004 // "<servername> <version> <available user modes> <available channel modes>"
 	RPL_TOPICWHOTIME    = 333 // "<channel> <who> <time>"
 	RPL_BOUNCE          = 005 // "Try server <server name>, port <port number>"
+	RPL_ISUPPORT        = 005 // "<token>{ <token>} :are supported by this server"
 	RPL_USERHOST        = 302 // Reply format used by USERHOST
 	RPL_ISON            = 303 // Reply format used by ISON
 	RPL_AWAY            = 301 // "<nick> :<away message>"
@@ -20,6 +21,9 @@ const (
 	RPL_WHOISIDLE       = 317 // "<nick> <integer> :seconds idle"
 	RPL_ENDOFWHOIS      = 318 // "<nick> :End of WHOIS list"
 	RPL_WHOISCHANNELS   = 319 // "<nick> :*( ( "@" / "+" ) <channel> " " )"
+	RPL_WHOISACCOUNT    = 330 // "<nick> <account> :is logged in as"
+	RPL_WHOISBOT        = 335 // "<nick> :is a bot on <network>"
+	RPL_WHOISACTUALLY   = 338 // "<nick> <ip> :actually using host"
 	RPL_WHOWASUSER      = 314 // "<nick> <user> <host> * :<real name>"
 	RPL_ENDOFWHOWAS     = 369 // "<nick> :End of WHOWAS"
 	RPL_LISTSTART       = 321 // :server 321 nick Channel :Users  Name
@@ -27,6 +31,7 @@ const (
 	RPL_LISTEND         = 323 // ":End of LIST"
 	RPL_UNIQOPIS        = 325 // "<channel> <nickname>"
 	RPL_CHANNELMODEIS   = 324 // "<channel> <mode> <mode params>"
+	RPL_CREATIONTIME    = 329 // "<channel> <creation time>"
 	RPL_NOTOPIC         = 331 // "<channel> :No topic is set"
 	RPL_TOPIC           = 332 // "<channel> :<topic>"
 	RPL_INVITING        = 341 // "<channel> <nick>"
@@ -88,6 +93,19 @@ const (
 	RPL_ADMINLOC2       = 258 // ":<admin info>"
 	RPL_ADMINEMAIL      = 259 // ":<admin info>"
 	RPL_TRYAGAIN        = 263 // "<command> :Please wait a while and try again."
+	RPL_SILELIST        = 271 // "<nick> <mask>"
+	RPL_ENDOFSILELIST   = 272 // ":End of Silence List"
+	RPL_ACCEPTLIST      = 281 // "<nick>"
+	RPL_ENDOFACCEPT     = 282 // ":End of /ACCEPT list"
+	RPL_TARGUMODEG      = 716 // "<nick> :is in +g mode (server-side ignore)"
+	RPL_TARGNOTIFY      = 717 // "<nick> :has been informed that you messaged them"
+	RPL_UMODEGMSG       = 718 // "<nick> <user>@<host> :is messaging you, and you have umode +g"
+	RPL_QUIETLIST       = 728 // "<channel> q <mask>"
+	RPL_ENDOFQUIETLIST  = 729 // "<channel> q :End of channel quiet list"
+	RPL_MONONLINE       = 730 // ":<nick>!<user>@<host>[,<nick>!<user>@<host>]*"
+	RPL_MONOFFLINE      = 731 // ":<nick>[,<nick>]*"
+	RPL_MONLIST         = 732 // ":<nick>[,<nick>]*"
+	RPL_ENDOFMONLIST    = 733 // ":End of MONITOR list"
 
 	// Error replies
 	ERR_NOSUCHNICK        = 401 // "<nickname> :No such nick/channel"
@@ -99,6 +117,7 @@ const (
 	ERR_TOOMANYTARGETS    = 407 // "<target> :<error code> recipients. <abort message>"
 	ERR_NOSUCHSERVICE     = 408 // "<service name> :No such service"
 	ERR_NOORIGIN          = 409 // ":No origin specified"
+	ERR_INVALIDCAPCMD     = 410 // "<command> :Invalid CAP command"
 	ERR_NORECIPIENT       = 411 // ":No recipient given (<command>)"
 	ERR_NOTEXTTOSEND      = 412 // ":No text to send"
 	ERR_NOTOPLEVEL        = 413 // "<mask> :No toplevel domain specified"
@@ -119,7 +138,11 @@ const (
 	ERR_NOLOGIN           = 444 // "<user> :User not logged in"
 	ERR_SUMMONDISABLED    = 445 // ":SUMMON has been disabled"
 	ERR_USERSDISABLED     = 446 // ":USERS has been disabled"
+	ERR_NONICKCHANGE      = 447 // "<nick> :Cannot change nickname while on <channel>"
 	ERR_NOTREGISTERED     = 451 // ":You have not registered"
+	ERR_ACCEPTFULL        = 456 // ":Accept list is full"
+	ERR_ACCEPTEXIST       = 457 // "<nick> :is already on your accept list"
+	ERR_ACCEPTNOT         = 458 // "<nick> :is not on your accept list"
 	ERR_NEEDMOREPARAMS    = 461 // "<command> :Not enough parameters"
 	ERR_ALREADYREGISTRED  = 462 // ":Unauthorized command (already registered)"
 	ERR_NOPERMFORHOST     = 463 // ":Your host isn't among the privileged"
@@ -127,6 +150,7 @@ const (
 	ERR_YOUREBANNEDCREEP  = 465 // ":You are banned from this server"
 	ERR_YOUWILLBEBANNED   = 466 // ""
 	ERR_KEYSET            = 467 // "<channel> :Channel key already set"
+	ERR_LINKCHANNEL       = 470 // "<channel> <forward channel> :Forwarding to another channel"
 	ERR_CHANNELISFULL     = 471 // "<channel> :Cannot join channel (+l)"
 	ERR_UNKNOWNMODE       = 472 // "<char> :is unknown mode char to me for <channel>"
 	ERR_INVITEONLYCHAN    = 473 // "<channel> :Cannot join channel (+i)"
@@ -134,15 +158,21 @@ const (
 	ERR_BADCHANNELKEY     = 475 // "<channel> :Cannot join channel (+k)"
 	ERR_BADCHANMASK       = 476 // "<channel> :Bad Channel Mask"
 	ERR_NOCHANMODES       = 477 // "<channel> :Channel doesn't support modes"
+	ERR_NEEDREGGEDNICK    = 477 // "<channel> :Cannot join channel (+R) - you need to be logged into your account"
 	ERR_BANLISTFULL       = 478 // "<channel> <char> :Channel list is full"
+	ERR_THROTTLE          = 480 // "<channel> :Cannot join channel (+j) - throttle exceeded, try again later"
 	ERR_NOPRIVILEGES      = 481 // ":Permission Denied- You're not an IRC operator"
 	ERR_CHANOPRIVSNEEDED  = 482 // "<channel> :You're not channel operator"
 	ERR_CANTKILLSERVER    = 483 // ":You can't kill a server!"
 	ERR_RESTRICTED        = 484 // ":Your connection is restricted!"
 	ERR_UNIQOPPRIVSNEEDED = 485 // ":You're not the original channel operator"
+	ERR_NONONREG          = 486 // "<nick> :You must log in to message this user"
 	ERR_NOOPERHOST        = 491 // ":No O-lines for your host"
+	ERR_DELAYREJOIN       = 495 // "<channel> :You must wait <seconds> seconds after being kicked to rejoin (+J)"
 	ERR_UMODEUNKNOWNFLAG  = 501 // ":Unknown MODE flag"
 	ERR_USERSDONTMATCH    = 502 // ":Cannot change mode for other users"
+	ERR_SILELISTFULL      = 511 // "<mask> :Your silence list is full"
+	ERR_MONLISTFULL       = 734 // "<limit> <targets> :Monitor list is full."
 )
 
 // Server version and name
@@ -165,26 +195,53 @@ const (
 	CHAN_MODE_OP            = 'o' // Channel operator
 	CHAN_MODE_VOICE         = 'v' // Voice privilege
 	CHAN_MODE_BAN           = 'b' // Ban mask
+	CHAN_MODE_EXCEPTION     = 'e' // Ban and quiet exception mask
+	CHAN_MODE_QUIET         = 'q' // Quiet mask
+	CHAN_MODE_FLOOD         = 'F' // Flood protection settings
+	CHAN_MODE_FORWARD       = 'f' // Forward refused joins to another channel
+	CHAN_MODE_JOIN_THROTTLE = 'j' // Limit joins to <joins>:<seconds>
+	CHAN_MODE_KICK_DELAY    = 'J' // Seconds a kicked user must wait before rejoining
+	CHAN_MODE_NO_COLOUR     = 'c' // Reject messages containing colour or formatting codes
+	CHAN_MODE_STRIP_COLOUR  = 'S' // Strip colour and formatting codes from messages
+	CHAN_MODE_NO_CTCP       = 'C' // Block CTCP other than ACTION
+	CHAN_MODE_REGISTERED    = 'R' // Only logged-in users may join
+	CHAN_MODE_MOD_UNREG     = 'M' // Users who are not logged in may not speak
+	CHAN_MODE_PERMANENT     = 'P' // Channel persists when empty (IRC operators only)
 )
 
+// STATUSMSG lists the membership prefixes that may precede a channel name to
+// address only members holding that status or higher.
+const STATUSMSG = "@+"
+
 // User modes
 const (
 	USER_MODE_INVISIBLE  = 'i' // Invisible
 	USER_MODE_OPERATOR   = 'o' // IRC Operator
 	USER_MODE_WALLOPS    = 'w' // Receive wallops
 	USER_MODE_RESTRICTED = 'r' // Restricted connection
+	USER_MODE_REGPM      = 'R' // Only accept private messages from logged-in users
+	USER_MODE_BOT        = 'B' // Marks the user as a bot
+	USER_MODE_DEAF       = 'D' // Do not receive channel messages
+	USER_MODE_CALLERID   = 'g' // Only accept private messages from users on the accept list
 )
 
+// USER_MODES lists the user modes advertised in RPL_MYINFO.
+const USER_MODES = "BDRgiow"
+
 // Maximum limits
 const (
 	MAX_CHANNELS_PER_USER   = 10
 	MAX_CLIENTS_PER_CHANNEL = 100
 	MAX_BANS_PER_CHANNEL    = 50
+	MAX_MODE_PARAMS         = 4 // Mode changes with a parameter per MODE command
+	MAX_MESSAGE_TARGETS     = 4 // Targets per PRIVMSG or NOTICE command
+	MAX_ACCEPT_ENTRIES      = 30
 	MAX_NICK_LENGTH         = 9
 	MAX_CHANNEL_NAME_LENGTH = 50
 	MAX_TOPIC_LENGTH        = 307
 	MAX_KICK_COMMENT_LENGTH = 307
 	MAX_AWAY_MESSAGE_LENGTH = 307
+	MAX_USERNAME_LENGTH     = 10
 )
 
 const (
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// maxDuration is the longest duration ParseDuration accepts. Anything longer
+// is refused rather than left to overflow.
+const maxDuration = 366 * 24 * time.Hour
+
+// ParseDuration parses durations such as "90", "2h" or "1d12h". A bare
+// number is taken as minutes. Durations over a year are rejected.
+func ParseDuration(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if minutes, err := strconv.Atoi(value); err == nil {
+		if minutes <= 0 || minutes > int(maxDuration/time.Minute) {
+			return 0, false
+		}
+		return time.Duration(minutes) * time.Minute, true
+	}
+
+	var total time.Duration
+	number := 0
+	hasDigits := false
+
+	for i := 0; i < len(value); i++ {
+		char := value[i]
+
+		if char >= '0' && char <= '9' {
+			number = number*10 + int(char-'0')
+			hasDigits = true
+			if number > int(maxDuration/time.Second) {
+				return 0, false
+			}
+			continue
+		}
+
+		unit, known := durationUnits[char|0x20]
+		if !known || !hasDigits || number > int(maxDuration/unit) {
+			return 0, false
+		}
+
+		total += time.Duration(number) * unit
+		if total > maxDuration {
+			return 0, false
+		}
+		number = 0
+		hasDigits = false
+	}
+
+	if hasDigits || total <= 0 {
+		return 0, false
+	}
+
+	return total, true
+}
+
+// FormatDuration renders a duration in the compact form accepted by
+// ParseDuration, e.g. "1d2h".
+func FormatDuration(d time.Duration) string {
+	if d < time.Second {
+		return "0s"
+	}
+
+	var builder strings.Builder
+	for _, unit := range []byte{'w', 'd', 'h', 'm', 's'} {
+		size := durationUnits[unit]
+		if d >= size {
+			builder.WriteString(strconv.Itoa(int(d / size)))
+			builder.WriteByte(unit)
+			d %= size
+		}
+	}
+
+	return builder.String()
+}
@@ -4,14 +4,28 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
-	IRCBold      = "\x02"
-	IRCItalic    = "\x1D"
-	IRCUnderline = "\x1F"
-	IRCColor     = "\x03"
-	IRCReset     = "\x0F"
+	IRCBold          = "\x02"
+	IRCItalic        = "\x1D"
+	IRCUnderline     = "\x1F"
+	IRCColor         = "\x03"
+	IRCReset         = "\x0F"
+	IRCHexColor      = "\x04"
+	IRCReverse       = "\x16"
+	IRCStrikethrough = "\x1E"
+	IRCMonospace     = "\x11"
+	IRCCTCP          = "\x01"
+)
+
+const formattingCodes = IRCBold + IRCItalic + IRCUnderline + IRCColor + IRCReset +
+	IRCHexColor + IRCReverse + IRCStrikethrough + IRCMonospace
+
+var (
+	colorCodeRegex    = regexp.MustCompile(`\x03(\d{1,2}(,\d{1,2})?)?`)
+	hexColorCodeRegex = regexp.MustCompile(`\x04([0-9a-fA-F]{6}(,[0-9a-fA-F]{6})?)?`)
 )
 
 var colorMap = map[string]string{
@@ -98,6 +112,62 @@ func FormatMOTD(text string) string {
 	return text
 }
 
+// HasFormatting reports whether text contains any mIRC formatting or colour
+// control codes.
+func HasFormatting(text string) bool {
+	return strings.ContainsAny(text, formattingCodes)
+}
+
+// StripFormatting removes mIRC formatting and colour codes from text,
+// including the colour numbers that follow a colour code.
+func StripFormatting(text string) string {
+	if !HasFormatting(text) {
+		return text
+	}
+
+	text = colorCodeRegex.ReplaceAllString(text, "")
+	text = hexColorCodeRegex.ReplaceAllString(text, "")
+
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(formattingCodes, r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// IsCTCP reports whether a message is a CTCP request or reply.
+func IsCTCP(text string) bool {
+	return strings.HasPrefix(text, IRCCTCP)
+}
+
+// CTCPCommand returns the upper-cased command of a CTCP message, such as
+// ACTION or VERSION, or an empty string if text is not CTCP.
+func CTCPCommand(text string) string {
+	if !IsCTCP(text) {
+		return ""
+	}
+
+	body := strings.TrimSuffix(strings.TrimPrefix(text, IRCCTCP), IRCCTCP)
+	command, _, _ := strings.Cut(body, " ")
+
+	return strings.ToUpper(command)
+}
+
+// TruncateText shortens text to at most maxBytes bytes without splitting a
+// UTF-8 character.
+func TruncateText(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
 func formatColorCode(code string) string {
 	if num, err := strconv.Atoi(code); err == nil && num < 10 {
 		return "0" + code
@@ -4,6 +4,7 @@ import (
 	"net"
 	"regexp"
 	"strings"
+	"time"
 )
 
 type HostMatcher struct {
@@ -182,3 +183,24 @@ func MatchesBanMask(clientMask, banMask string) bool {
 
 	return nickMatch && userMatch && hostMatch
 }
+
+// ParseTimedMask splits a list mode parameter of the form ~t:<duration>:<mask>
+// into its mask and duration. Masks without the prefix are returned as-is
+// with a zero duration.
+func ParseTimedMask(param string) (string, time.Duration, bool) {
+	if !strings.HasPrefix(param, "~t:") {
+		return param, 0, true
+	}
+
+	durationStr, mask, found := strings.Cut(param[3:], ":")
+	if !found || mask == "" {
+		return "", 0, false
+	}
+
+	duration, ok := ParseDuration(durationStr)
+	if !ok {
+		return "", 0, false
+	}
+
+	return mask, duration, true
+}
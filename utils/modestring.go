@@ -0,0 +1,81 @@
+package utils
+
+import "strings"
+
+// Mode is a single letter of a mode string with its direction and parameter.
+type Mode struct {
+	Adding bool
+	Letter rune
+	Param  string
+}
+
+// ParseModeString splits a mode string such as "+ov-b" and its parameters
+// into individual changes. takesParam reports whether a letter consumes a
+// parameter in the given direction; a change left without one has an empty
+// Param. Only the first maxParams changes that take a parameter are
+// returned, and anything after them is dropped, as advertised by MODES.
+func ParseModeString(modeString string, params []string, takesParam func(letter rune, adding bool) bool, maxParams int) []Mode {
+	var modes []Mode
+
+	adding := true
+	paramIndex := 0
+	paramCount := 0
+
+	for _, char := range modeString {
+		switch char {
+		case '+':
+			adding = true
+			continue
+		case '-':
+			adding = false
+			continue
+		}
+
+		mode := Mode{Adding: adding, Letter: char}
+
+		if takesParam(char, adding) {
+			if paramCount >= maxParams {
+				break
+			}
+			paramCount++
+
+			if paramIndex < len(params) {
+				mode.Param = params[paramIndex]
+				paramIndex++
+			}
+		}
+
+		modes = append(modes, mode)
+	}
+
+	return modes
+}
+
+// FormatModeString renders changes as a single mode string followed by their
+// parameters, e.g. "+ov-b alice alice *!*@host". A sign is only written when
+// the direction changes. Changes whose Param is empty add no parameter.
+func FormatModeString(modes []Mode) string {
+	var letters strings.Builder
+	var params []string
+
+	for i, mode := range modes {
+		if i == 0 || mode.Adding != modes[i-1].Adding {
+			if mode.Adding {
+				letters.WriteByte('+')
+			} else {
+				letters.WriteByte('-')
+			}
+		}
+
+		letters.WriteRune(mode.Letter)
+		if mode.Param != "" {
+			params = append(params, mode.Param)
+		}
+	}
+
+	if len(params) == 0 {
+		return letters.String()
+	}
+
+	return letters.String() + " " + strings.Join(params, " ")
+}
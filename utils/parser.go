@@ -118,6 +118,15 @@ func IsValidChannelName(name string) bool {
 	return true
 }
 
+// SplitStatusTarget separates a STATUSMSG prefix such as the '@' in "@#ops"
+// from the channel it addresses. The prefix is 0 for ordinary targets.
+func SplitStatusTarget(target string) (byte, string) {
+	if len(target) > 1 && strings.IndexByte(STATUSMSG, target[0]) >= 0 && IsValidChannelName(target[1:]) {
+		return target[0], target[1:]
+	}
+	return 0, target
+}
+
 func IsValidNickname(nick string) bool {
 	if nick == "" {
 		return false
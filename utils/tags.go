@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
+
+const ServerTimeFormat = "2006-01-02T15:04:05.000Z"
+
+func GenerateMsgID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return strings.ReplaceAll(time.Now().UTC().Format("20060102150405.000000000"), ".", "")
+	}
+	return hex.EncodeToString(buf)
+}
+
+func FormatServerTime(t time.Time) string {
+	return t.UTC().Format(ServerTimeFormat)
+}
+
+func ParseServerTime(value string) (time.Time, bool) {
+	t, err := time.Parse(ServerTimeFormat, value)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+	return t, true
+}
+
+// FormatTags renders IRCv3 message tags as a line prefix, including the
+// leading '@' and trailing space. Tags are emitted in a stable order.
+func FormatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString("@")
+	for i, key := range keys {
+		if i > 0 {
+			builder.WriteString(";")
+		}
+		builder.WriteString(key)
+		if value := tags[key]; value != "" {
+			builder.WriteString("=")
+			builder.WriteString(escapeTagValue(value))
+		}
+	}
+	builder.WriteString(" ")
+
+	return builder.String()
+}
+
+// ParseTags parses the tags of a message, without the leading '@'.
+func ParseTags(raw string) map[string]string {
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(raw, ";") {
+		key, value, _ := strings.Cut(tag, "=")
+		if key != "" {
+			tags[key] = unescapeTagValue(value)
+		}
+	}
+	return tags
+}
+
+func escapeTagValue(value string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\:",
+		" ", "\\s",
+		"\r", "\\r",
+		"\n", "\\n",
+	)
+	return replacer.Replace(value)
+}
+
+func unescapeTagValue(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			builder.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		if i == len(value) {
+			break
+		}
+		switch value[i] {
+		case ':':
+			builder.WriteByte(';')
+		case 's':
+			builder.WriteByte(' ')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'n':
+			builder.WriteByte('\n')
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String()
+}